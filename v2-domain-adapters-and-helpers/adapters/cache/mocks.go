@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+type Mock struct {
+	GetFn func(ctx context.Context, key string, record interface{}) error
+	SetFn func(ctx context.Context, key string, record interface{}) error
+}
+
+func (m Mock) Get(ctx context.Context, key string, record interface{}) error {
+	if m.GetFn != nil {
+		return m.GetFn(ctx, key, record)
+	}
+	return domain.NotFoundErr("record-not-found", map[string]interface{}{
+		"func":      "cache.Mock.Get",
+		"input_key": key,
+	})
+}
+
+func (m Mock) Set(ctx context.Context, key string, record interface{}) error {
+	if m.SetFn != nil {
+		return m.SetFn(ctx, key, record)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// reserveScript implements the GCRA algorithm (an equivalent of a token bucket)
+// atomically on redis, so all the instances of the API share the same limits.
+//
+// It uses the redis clock instead of the clock of each instance
+// so clock skew between instances doesn't affect the limits.
+//
+// All durations are in microseconds.
+var reserveScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local max_wait = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local wait = new_tat - burst * interval - now
+if wait > max_wait then
+	return {0, wait}
+end
+if wait < 0 then
+	wait = 0
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil((new_tat - now) / 1000) + 1)
+return {1, wait}
+`)
+
+// Reserve implements the ratelimit.Limiter interface
+func (c Client) Reserve(
+	ctx context.Context,
+	key string,
+	interval time.Duration,
+	burst int,
+	maxWait time.Duration,
+) (wait time.Duration, ok bool, _ error) {
+	result, err := reserveScript.Run(ctx, c.redis, []string{key},
+		interval.Microseconds(),
+		burst,
+		maxWait.Microseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, false, domain.InternalErr("error-reserving-rate-limit-token-on-redis", map[string]interface{}{
+			"func":      "redis.Client.Reserve",
+			"error":     err.Error(),
+			"input_key": key,
+		})
+	}
+
+	return time.Duration(result[1]) * time.Microsecond, result[0] == 1, nil
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import "context"
+
+// Provider records measurements on named histograms
+//
+// Usage example:
+//
+//	metricsClient.Observe(ctx, "rest_client_request_duration_seconds", 0.25, metrics.Labels{
+//	  "host": "api.foursquare.com",
+//	})
+type Provider interface {
+	Observe(ctx context.Context, name string, value float64, labels Labels)
+}
+
+type Labels = map[string]string
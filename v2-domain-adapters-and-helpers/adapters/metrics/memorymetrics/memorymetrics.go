@@ -0,0 +1,172 @@
+package memorymetrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/metrics"
+)
+
+// DefaultBuckets are the upper bounds used for histograms with no
+// configured buckets, they are adequate for latencies in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Client keeps all histograms in memory and can write them
+// on the Prometheus text format, e.g. for serving a /metrics endpoint.
+type Client struct {
+	mutex  sync.Mutex
+	series map[string]*Histogram
+
+	buckets map[string][]float64
+}
+
+// Histogram is a snapshot of the observations of a single
+// metric name with a single set of labels.
+type Histogram struct {
+	Name   string
+	Labels metrics.Labels
+
+	// Buckets are the upper bounds of each bucket and Counts
+	// the number of observations less or equal to each of them.
+	Buckets []float64
+	Counts  []uint64
+
+	Count uint64
+	Sum   float64
+}
+
+// New instantiates a new Client, bucketsByName allows choosing the
+// buckets of each histogram, all others use the DefaultBuckets.
+func New(bucketsByName map[string][]float64) *Client {
+	return &Client{
+		series:  map[string]*Histogram{},
+		buckets: bucketsByName,
+	}
+}
+
+// Observe implements the metrics.Provider interface
+func (c *Client) Observe(ctx context.Context, name string, value float64, labels metrics.Labels) {
+	key := seriesKey(name, labels)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	h, ok := c.series[key]
+	if !ok {
+		buckets, ok := c.buckets[name]
+		if !ok {
+			buckets = DefaultBuckets
+		}
+
+		labelsCopy := make(metrics.Labels, len(labels))
+		for k, v := range labels {
+			labelsCopy[k] = v
+		}
+
+		h = &Histogram{
+			Name:    name,
+			Labels:  labelsCopy,
+			Buckets: buckets,
+			Counts:  make([]uint64, len(buckets)),
+		}
+		c.series[key] = h
+	}
+
+	for i, bound := range h.Buckets {
+		if value <= bound {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += value
+}
+
+// Histograms returns a copy of all histograms sorted by name and labels
+func (c *Client) Histograms() []Histogram {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	histograms := make([]Histogram, 0, len(keys))
+	for _, key := range keys {
+		h := *c.series[key]
+		h.Counts = append([]uint64(nil), h.Counts...)
+		histograms = append(histograms, h)
+	}
+	return histograms
+}
+
+// WriteText writes all histograms on the Prometheus text exposition format
+func (c *Client) WriteText(w io.Writer) error {
+	var lastName string
+	for _, h := range c.Histograms() {
+		if h.Name != lastName {
+			if _, err := fmt.Fprintf(w, "# TYPE %s histogram\n", h.Name); err != nil {
+				return err
+			}
+			lastName = h.Name
+		}
+
+		for i, bound := range h.Buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.Name, formatLabels(h.Labels, "le", formatFloat(bound)), h.Counts[i]); err != nil {
+				return err
+			}
+		}
+
+		_, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.Name, formatLabels(h.Labels, "le", "+Inf"), h.Count,
+			h.Name, formatLabels(h.Labels), formatFloat(h.Sum),
+			h.Name, formatLabels(h.Labels), h.Count,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func seriesKey(name string, labels metrics.Labels) string {
+	return name + formatLabels(labels)
+}
+
+// formatLabels formats the labels sorted by name, e.g.: `{host="foo",method="GET"}`,
+// extra is an optional list of name and value pairs appended after the labels.
+func formatLabels(labels metrics.Labels, extra ...string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+len(extra)/2)
+	for _, name := range names {
+		pairs = append(pairs, name+"="+strconv.Quote(labels[name]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+"="+strconv.Quote(extra[i+1]))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
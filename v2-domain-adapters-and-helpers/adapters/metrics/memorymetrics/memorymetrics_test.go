@@ -0,0 +1,51 @@
+package memorymetrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/metrics"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should write the histograms on the prometheus text format", func(t *testing.T) {
+		client := New(map[string][]float64{
+			"fake_size": {10, 100},
+		})
+
+		client.Observe(ctx, "fake_size", 5, metrics.Labels{"host": "b.host"})
+		client.Observe(ctx, "fake_size", 50, metrics.Labels{"host": "b.host"})
+		client.Observe(ctx, "fake_size", 500, metrics.Labels{"host": "b.host"})
+		client.Observe(ctx, "fake_size", 1, metrics.Labels{"host": "a.host"})
+
+		var buf bytes.Buffer
+		err := client.WriteText(&buf)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, buf.String(), `# TYPE fake_size histogram
+fake_size_bucket{host="a.host",le="10"} 1
+fake_size_bucket{host="a.host",le="100"} 1
+fake_size_bucket{host="a.host",le="+Inf"} 1
+fake_size_sum{host="a.host"} 1
+fake_size_count{host="a.host"} 1
+fake_size_bucket{host="b.host",le="10"} 1
+fake_size_bucket{host="b.host",le="100"} 2
+fake_size_bucket{host="b.host",le="+Inf"} 3
+fake_size_sum{host="b.host"} 555
+fake_size_count{host="b.host"} 3
+`)
+	})
+
+	t.Run("should use the default buckets for unknown names", func(t *testing.T) {
+		client := New(nil)
+		client.Observe(ctx, "fake_duration", 0.3, nil)
+
+		histograms := client.Histograms()
+		tt.AssertEqual(t, len(histograms), 1)
+		tt.AssertEqual(t, histograms[0].Buckets, DefaultBuckets)
+		tt.AssertEqual(t, histograms[0].Count, uint64(1))
+	})
+}
@@ -0,0 +1,14 @@
+package metrics
+
+import "context"
+
+// Mock ...
+type Mock struct {
+	ObserveFn func(ctx context.Context, name string, value float64, labels Labels)
+}
+
+func (m Mock) Observe(ctx context.Context, name string, value float64, labels Labels) {
+	if m.ObserveFn != nil {
+		m.ObserveFn(ctx, name, value, labels)
+	}
+}
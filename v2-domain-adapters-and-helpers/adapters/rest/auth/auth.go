@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+// TokenSource provides the credentials injected on each request
+type TokenSource interface {
+	// Token returns a valid token, if refresh is true any cached
+	// token must be discarded and a new one should be fetched.
+	Token(ctx context.Context, refresh bool) (string, error)
+}
+
+// Client is a rest.Provider decorator that adds an authentication
+// header to every request before forwarding it to the decorated provider.
+type Client struct {
+	next   rest.Provider
+	tokens TokenSource
+
+	header string
+	scheme string
+
+	// retryUnauthorized makes the client fetch a new token and
+	// try again once when the upstream answers with a 401.
+	retryUnauthorized bool
+}
+
+// NewAPIKey instantiates a client that sends a static API key
+// on the input header, e.g.:
+//
+//	auth.NewAPIKey(restClient, "X-API-Key", apiKey)
+func NewAPIKey(next rest.Provider, header string, key string) Client {
+	return Client{
+		next:   next,
+		tokens: staticToken(key),
+		header: header,
+	}
+}
+
+// NewBearer instantiates a client that sends the tokens provided by the
+// input TokenSource on the `Authorization: Bearer <token>` header.
+//
+// If the upstream rejects a token with a 401 a new token is fetched
+// and the request is retried once.
+func NewBearer(next rest.Provider, tokens TokenSource) Client {
+	return Client{
+		next:              next,
+		tokens:            tokens,
+		header:            "Authorization",
+		scheme:            "Bearer ",
+		retryUnauthorized: true,
+	}
+}
+
+// Get implements the rest.Provider interface
+func (c Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, c.next.Get, url, data)
+}
+
+// Post implements the rest.Provider interface
+func (c Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, c.next.Post, url, data)
+}
+
+// Put implements the rest.Provider interface
+func (c Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, c.next.Put, url, data)
+}
+
+// Patch implements the rest.Provider interface
+func (c Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, c.next.Patch, url, data)
+}
+
+// Delete implements the rest.Provider interface
+func (c Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, c.next.Delete, url, data)
+}
+
+// Stream implements the rest.Streamer interface
+func (c Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	var streamResp rest.StreamResponse
+	_, err := c.do(ctx, func(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+		// Closing the body of the unauthorized response before retrying:
+		if streamResp.Body != nil {
+			streamResp.Body.Close()
+		}
+
+		var err error
+		streamResp, err = rest.Stream(ctx, c.next, method, url, data)
+		return rest.Response{
+			Header:     streamResp.Header,
+			StatusCode: streamResp.StatusCode,
+		}, err
+	}, url, data)
+	return streamResp, err
+}
+
+type requestFn func(ctx context.Context, url string, data rest.RequestData) (rest.Response, error)
+
+func (c Client) do(ctx context.Context, fn requestFn, url string, data rest.RequestData) (rest.Response, error) {
+	if c.retryUnauthorized {
+		// Readers can only be sent once, so we buffer them in case we need to retry:
+		if reader, ok := data.Body.(io.Reader); ok {
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				return rest.Response{}, err
+			}
+			data.Body = body
+		}
+	}
+
+	resp, err := c.send(ctx, fn, url, data, false)
+	if !c.retryUnauthorized || !isUnauthorized(resp, err) {
+		return resp, err
+	}
+
+	return c.send(ctx, fn, url, data, true)
+}
+
+func (c Client) send(ctx context.Context, fn requestFn, url string, data rest.RequestData, refresh bool) (rest.Response, error) {
+	token, err := c.tokens.Token(ctx, refresh)
+	if err != nil {
+		return rest.Response{}, err
+	}
+
+	// Copying the headers so we never change the map of the caller:
+	headers := make(map[string]string, len(data.Headers)+1)
+	for k, v := range data.Headers {
+		headers[k] = v
+	}
+	headers[c.header] = c.scheme + token
+	data.Headers = headers
+
+	return fn(ctx, url, data)
+}
+
+func isUnauthorized(resp rest.Response, err error) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	var statusErr rest.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
+
+type staticToken string
+
+func (s staticToken) Token(ctx context.Context, refresh bool) (string, error) {
+	return string(s), nil
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// ClientCredentials is a TokenSource that fetches tokens using the
+// OAuth2 client credentials grant and caches them until shortly
+// before they expire.
+//
+// Usage example:
+//
+//	tokens := auth.NewClientCredentials(httpClient, cacheClient, auth.ClientCredentialsConfig{
+//		TokenURL:     "https://auth.example.com/oauth/token",
+//		ClientID:     clientID,
+//		ClientSecret: clientSecret,
+//	})
+//	restClient := auth.NewBearer(httpClient, tokens)
+type ClientCredentials struct {
+	rest   rest.Provider
+	cache  cache.Provider
+	config ClientCredentialsConfig
+
+	// group makes concurrent requests share a single token fetch
+	group *singleflight.Group
+	now   func() time.Time
+}
+
+// ClientCredentialsConfig describes how to fetch the tokens
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// By default the credentials are sent on the `Authorization: Basic` header,
+	// set this to true for servers that expect them on the request body instead.
+	CredentialsInBody bool
+
+	// RefreshBefore is how long before the expiration a token is
+	// replaced by a new one. Defaults to 1 minute.
+	RefreshBefore time.Duration
+
+	// DefaultExpiration is used when the server omits the `expires_in`
+	// field of the response. Defaults to 1 hour.
+	DefaultExpiration time.Duration
+
+	// KeyPrefix is prepended to the cache keys. Defaults to "oauth2:".
+	KeyPrefix string
+
+	// Logger is used for reporting cache errors and is optional
+	Logger log.Provider
+}
+
+// cachedToken is the record saved on the cache for each token
+type cachedToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewClientCredentials instantiates a new ClientCredentials token source,
+// the input rest.Provider is used for calling the token endpoint and
+// should not be decorated with the auth Client itself.
+func NewClientCredentials(rest rest.Provider, cache cache.Provider, config ClientCredentialsConfig) *ClientCredentials {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = time.Minute
+	}
+	if config.DefaultExpiration <= 0 {
+		config.DefaultExpiration = time.Hour
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "oauth2:"
+	}
+
+	return &ClientCredentials{
+		rest:   rest,
+		cache:  cache,
+		config: config,
+		group:  &singleflight.Group{},
+		now:    time.Now,
+	}
+}
+
+// Token implements the TokenSource interface
+func (c *ClientCredentials) Token(ctx context.Context, refresh bool) (string, error) {
+	key := c.cacheKey()
+
+	if !refresh {
+		var cached cachedToken
+		err := c.cache.Get(ctx, key, &cached)
+		if err == nil && c.now().Add(c.config.RefreshBefore).Before(cached.ExpiresAt) {
+			return cached.AccessToken, nil
+		}
+	}
+
+	token, err, _ := c.group.Do(key, func() (interface{}, error) {
+		return c.fetch(ctx, key)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token.(string), nil
+}
+
+func (c *ClientCredentials) fetch(ctx context.Context, key string) (string, error) {
+	form := rest.Form{
+		"grant_type": {"client_credentials"},
+	}
+	if len(c.config.Scopes) > 0 {
+		form["scope"] = []string{strings.Join(c.config.Scopes, " ")}
+	}
+
+	headers := map[string]string{
+		"Accept": "application/json",
+	}
+	if c.config.CredentialsInBody {
+		form["client_id"] = []string{c.config.ClientID}
+		form["client_secret"] = []string{c.config.ClientSecret}
+	} else {
+		credentials := url.QueryEscape(c.config.ClientID) + ":" + url.QueryEscape(c.config.ClientSecret)
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+
+	resp, err := rest.PostJSON[tokenResponse](ctx, c.rest, c.config.TokenURL, rest.RequestData{
+		Headers: headers,
+		Body:    form,
+	})
+	if err != nil {
+		return "", domain.InternalErr("unable-to-fetch-oauth2-token", map[string]interface{}{
+			"token_url": rest.RedactURL(c.config.TokenURL),
+			"error":     err.Error(),
+		})
+	}
+	if resp.AccessToken == "" {
+		return "", domain.InternalErr("oauth2-token-response-missing-access-token", map[string]interface{}{
+			"token_url": rest.RedactURL(c.config.TokenURL),
+		})
+	}
+
+	expiresIn := c.config.DefaultExpiration
+	if resp.ExpiresIn > 0 {
+		expiresIn = time.Duration(resp.ExpiresIn) * time.Second
+	}
+
+	err = c.cache.Set(ctx, key, cachedToken{
+		AccessToken: resp.AccessToken,
+		ExpiresAt:   c.now().Add(expiresIn),
+	})
+	if err != nil && c.config.Logger != nil {
+		c.config.Logger.Warn(ctx, "unable-to-save-oauth2-token-on-cache", log.Body{
+			"token_url": rest.RedactURL(c.config.TokenURL),
+			"error":     err.Error(),
+		})
+	}
+
+	return resp.AccessToken, nil
+}
+
+// cacheKey identifies the credentials without using the secret as part of the key
+func (c *ClientCredentials) cacheKey() string {
+	hash := sha256.New()
+	hash.Write([]byte(c.config.TokenURL + "\n" + c.config.ClientID + "\n" + strings.Join(c.config.Scopes, " ")))
+	return c.config.KeyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Form is sent as an `application/x-www-form-urlencoded` body
+//
+// Usage example:
+//
+//	resp, err := restClient.Post(ctx, url, rest.RequestData{
+//		Body: rest.Form{"grant_type": {"client_credentials"}},
+//	})
+type Form url.Values
+
+// Multipart is sent as a `multipart/form-data` body
+//
+// Usage example:
+//
+//	resp, err := restClient.Post(ctx, url, rest.RequestData{
+//		Body: rest.Multipart{
+//			Fields: url.Values{"name": {"fakeName"}},
+//			Files: []rest.File{{
+//				FieldName: "photo",
+//				FileName:  "photo.png",
+//				Content:   pngBytes,
+//			}},
+//		},
+//	})
+type Multipart struct {
+	Fields url.Values
+	Files  []File
+}
+
+// File describes a file sent as part of a Multipart body
+type File struct {
+	FieldName string
+	FileName  string
+
+	// ContentType defaults to "application/octet-stream"
+	ContentType string
+
+	Content []byte
+}
+
+// EncodeBody encodes the input body according to its type and returns the
+// Content-Type it should be sent with, or an empty string if unknown:
+//
+//   - nil, []byte and string are sent as they are with no Content-Type;
+//   - Form is sent as `application/x-www-form-urlencoded`;
+//   - Multipart is sent as `multipart/form-data`;
+//   - Anything else is marshaled as `application/json`.
+//
+// Since readers can only be read once they are not accepted here,
+// each provider should decide how to deal with them.
+func EncodeBody(body interface{}) (rawBody []byte, contentType string, err error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, "", nil
+	case []byte:
+		return body, "", nil
+	case string:
+		return []byte(body), "", nil
+	case Form:
+		return []byte(url.Values(body).Encode()), "application/x-www-form-urlencoded", nil
+	case Multipart:
+		return encodeMultipart(body)
+	case io.Reader:
+		return nil, "", fmt.Errorf("rest: readers can't be encoded by EncodeBody")
+	default:
+		rawBody, err = json.Marshal(body)
+		return rawBody, "application/json", err
+	}
+}
+
+func encodeMultipart(body Multipart) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, name := range sortedKeys(body.Fields) {
+		for _, value := range body.Fields[name] {
+			err := writer.WriteField(name, value)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	for _, file := range body.Files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     file.FieldName,
+			"filename": file.FileName,
+		}))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		_, err = part.Write(file.Content)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// FormBody returns the fields of a form body, it works both for
+// rest.Form bodies and for bodies that were already encoded,
+// which is useful for asserting on the bodies received by a Mock.
+func (d RequestData) FormBody() (url.Values, error) {
+	switch body := d.Body.(type) {
+	case Form:
+		return url.Values(body), nil
+	case []byte:
+		return url.ParseQuery(string(body))
+	case string:
+		return url.ParseQuery(body)
+	}
+
+	return nil, fmt.Errorf("rest: body of type %T is not a form", d.Body)
+}
+
+// MultipartBody returns the fields and files of a multipart body,
+// it works both for rest.Multipart bodies and for bodies that were already
+// encoded, in which case the boundary is read from the Content-Type header.
+//
+// It is useful for asserting on the bodies received by a Mock.
+func (d RequestData) MultipartBody() (Multipart, error) {
+	var rawBody []byte
+	switch body := d.Body.(type) {
+	case Multipart:
+		return body, nil
+	case []byte:
+		rawBody = body
+	case string:
+		rawBody = []byte(body)
+	default:
+		return Multipart{}, fmt.Errorf("rest: body of type %T is not multipart", d.Body)
+	}
+
+	_, params, err := mime.ParseMediaType(d.header("Content-Type"))
+	if err != nil {
+		return Multipart{}, fmt.Errorf("rest: unable to parse multipart Content-Type: %w", err)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(rawBody), params["boundary"])
+	result := Multipart{
+		Fields: url.Values{},
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return Multipart{}, err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return Multipart{}, err
+		}
+
+		if part.FileName() == "" {
+			result.Fields.Add(part.FormName(), string(content))
+			continue
+		}
+
+		result.Files = append(result.Files, File{
+			FieldName:   part.FormName(),
+			FileName:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     content,
+		})
+	}
+}
+
+// header returns the value of a header ignoring its capitalization
+func (d RequestData) header(name string) string {
+	for k, v := range d.Headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
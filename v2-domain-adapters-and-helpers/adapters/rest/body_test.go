@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/url"
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestRequestBodies(t *testing.T) {
+	t.Run("should parse encoded forms", func(t *testing.T) {
+		rawBody, contentType, err := EncodeBody(Form{"name": {"fake name"}, "tags": {"a", "b"}})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, contentType, "application/x-www-form-urlencoded")
+
+		form, err := RequestData{Body: rawBody}.FormBody()
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, form, url.Values{"name": {"fake name"}, "tags": {"a", "b"}})
+	})
+
+	t.Run("should parse encoded multipart bodies", func(t *testing.T) {
+		body := Multipart{
+			Fields: url.Values{"name": {"fakeName"}},
+			Files: []File{{
+				FieldName: "photo",
+				FileName:  "photo.png",
+				Content:   []byte("fakePNG"),
+			}},
+		}
+
+		rawBody, contentType, err := EncodeBody(body)
+		tt.AssertNoErr(t, err)
+
+		parsed, err := RequestData{
+			Headers: map[string]string{"content-type": contentType},
+			Body:    rawBody,
+		}.MultipartBody()
+		tt.AssertNoErr(t, err)
+
+		body.Files[0].ContentType = "application/octet-stream"
+		tt.AssertEqual(t, parsed, body)
+	})
+
+	t.Run("should return typed bodies as they are", func(t *testing.T) {
+		body := Multipart{Fields: url.Values{"name": {"fakeName"}}}
+		parsed, err := RequestData{Body: body}.MultipartBody()
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, parsed, body)
+
+		_, err = RequestData{Body: map[string]string{}}.FormBody()
+		tt.AssertErrContains(t, err, "not a form")
+	})
+}
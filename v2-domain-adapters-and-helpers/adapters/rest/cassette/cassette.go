@@ -0,0 +1,527 @@
+package cassette
+
+// This package is meant to be used on tests, it allows us to record real
+// requests to an upstream API once and then replay them deterministically
+// on every following test run, so these tests can run offline but still
+// use realistic payloads.
+//
+// Recording is usually done by running the tests with real credentials, e.g.:
+//
+//	CASSETTE_MODE=record go test ./domain/venues/...
+//
+// Note that all sensitive query params, headers and request body fields are
+// scrubbed before being written to the fixture file, so it is safe to commit them.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+// Mode describes if the cassette should record or replay the interactions
+type Mode string
+
+const (
+	// ModeReplay serves responses from the fixture file and fails
+	// on any request that was not recorded.
+	ModeReplay Mode = "replay"
+
+	// ModeRecord sends all requests to the decorated rest.Provider and saves
+	// the request/response pairs to the fixture file when `Save()` is called.
+	ModeRecord Mode = "record"
+)
+
+// Redacted is the value used for replacing sensitive information
+const Redacted = rest.Redacted
+
+// Config describes the configurations of the cassette
+type Config struct {
+	Mode Mode
+
+	// Path is the path to the fixture file
+	Path string
+
+	// ScrubQueryParams lists the query params that should never be saved
+	// to the fixture file, the fields with the same names are also scrubbed
+	// from form-encoded and JSON request bodies. Defaults to `rest.SensitiveQueryParams`.
+	ScrubQueryParams []string
+
+	// ScrubHeaders lists the response headers that should never be saved
+	// to the fixture file. Defaults to `Authorization`, `Cookie`, `Set-Cookie`
+	// and `X-Api-Key`.
+	ScrubHeaders []string
+}
+
+// Client is a rest.Provider decorator that records and replays interactions
+type Client struct {
+	next   rest.Provider
+	config Config
+
+	mutex        sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// Interaction is a request/response pair as stored on the fixture file
+type Interaction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	RequestBody string `json:"request_body,omitempty"`
+
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+
+	// Body is only used when the response is not valid JSON,
+	// otherwise we save it as BodyJSON so it is easier to read:
+	Body     string          `json:"body,omitempty"`
+	BodyJSON json.RawMessage `json:"body_json,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// New instantiates a new cassette decorating the input rest.Provider,
+// on replay mode the `next` argument is never used and might be nil.
+func New(next rest.Provider, config Config) (*Client, error) {
+	if config.Mode == "" {
+		config.Mode = ModeReplay
+	}
+	if config.ScrubQueryParams == nil {
+		config.ScrubQueryParams = rest.SensitiveQueryParams
+	}
+	if config.ScrubHeaders == nil {
+		config.ScrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	}
+
+	c := &Client{
+		next:   next,
+		config: config,
+	}
+
+	if config.Mode == ModeRecord {
+		return c, nil
+	}
+
+	rawJSON, err := os.ReadFile(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: unable to read fixture file: %w", err)
+	}
+
+	var f fixture
+	err = json.Unmarshal(rawJSON, &f)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: unable to parse fixture file '%s': %w", config.Path, err)
+	}
+
+	c.interactions = f.Interactions
+	c.used = make([]bool, len(f.Interactions))
+	return c, nil
+}
+
+// Get implements the rest.Provider interface
+func (c *Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, "GET", url, data)
+}
+
+// Post implements the rest.Provider interface
+func (c *Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, "POST", url, data)
+}
+
+// Put implements the rest.Provider interface
+func (c *Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, "PUT", url, data)
+}
+
+// Patch implements the rest.Provider interface
+func (c *Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, "PATCH", url, data)
+}
+
+// Delete implements the rest.Provider interface
+func (c *Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.do(ctx, "DELETE", url, data)
+}
+
+// Save writes all the recorded interactions to the fixture file,
+// replacing any previous recordings of the same requests.
+//
+// On replay mode it does nothing.
+func (c *Client) Save() error {
+	if c.config.Mode != ModeRecord {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Interactions recorded previously are kept unless they were recorded again,
+	// so tests sharing the same fixture file can be recorded separately:
+	var f fixture
+	rawJSON, err := os.ReadFile(c.config.Path)
+	if err == nil {
+		err = json.Unmarshal(rawJSON, &f)
+		if err != nil {
+			return fmt.Errorf("cassette: unable to parse fixture file '%s': %w", c.config.Path, err)
+		}
+	}
+
+	interactions := []Interaction{}
+	for _, old := range f.Interactions {
+		if !containsRequest(c.interactions, old) {
+			interactions = append(interactions, old)
+		}
+	}
+	interactions = append(interactions, c.interactions...)
+
+	rawJSON, err = json.MarshalIndent(fixture{
+		Interactions: interactions,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: unable to marshal interactions: %w", err)
+	}
+
+	return os.WriteFile(c.config.Path, append(rawJSON, '\n'), 0644)
+}
+
+func (c *Client) do(ctx context.Context, method string, rawURL string, data rest.RequestData) (rest.Response, error) {
+	// The query params are merged into the URL so they can be scrubbed and matched:
+	rawURL, err := rest.AppendQuery(rawURL, data.Query)
+	if err != nil {
+		return rest.Response{}, fmt.Errorf("cassette: unable to parse request URL: %w", err)
+	}
+	data.Query = nil
+
+	scrubbedURL, secrets := c.scrubURL(rawURL)
+
+	requestBody, err := readBody(&data)
+	if err != nil {
+		return rest.Response{}, fmt.Errorf("cassette: unable to read request body: %w", err)
+	}
+
+	// The body is matched after scrubbing, the same way it is stored:
+	requestBody, bodySecrets := c.scrubBody(requestBody)
+	secrets = append(secrets, bodySecrets...)
+
+	if c.config.Mode == ModeReplay {
+		return c.replay(method, scrubbedURL, requestBody)
+	}
+
+	resp, err := c.callNext(ctx, method, rawURL, data)
+
+	interaction := Interaction{
+		Method:      method,
+		URL:         scrubbedURL,
+		RequestBody: requestBody,
+		StatusCode:  resp.StatusCode,
+		Header:      c.scrubHeader(resp.Header),
+	}
+	if json.Valid(resp.Body) {
+		interaction.BodyJSON = resp.Body
+	} else {
+		interaction.Body = string(resp.Body)
+	}
+	if err != nil {
+		interaction.Error = scrubString(err.Error(), secrets)
+	}
+
+	c.mutex.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.mutex.Unlock()
+
+	return resp, err
+}
+
+func (c *Client) replay(method string, scrubbedURL string, requestBody string) (rest.Response, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// We use the first unused match so repeated requests can have different
+	// responses, and if all of them were used we repeat the last one:
+	match := -1
+	for i, interaction := range c.interactions {
+		if interaction.Method != method || interaction.URL != scrubbedURL || interaction.RequestBody != requestBody {
+			continue
+		}
+
+		match = i
+		if !c.used[i] {
+			break
+		}
+	}
+	if match == -1 {
+		return rest.Response{}, fmt.Errorf(
+			"cassette: no recorded interaction matches the request: %s %s, body: '%s'",
+			method, scrubbedURL, requestBody,
+		)
+	}
+	c.used[match] = true
+
+	interaction := c.interactions[match]
+	body := []byte(interaction.Body)
+	if interaction.BodyJSON != nil {
+		// The fixture file is indented for readability, so we compact
+		// the body back to the format usually returned by APIs:
+		var buf bytes.Buffer
+		err := json.Compact(&buf, interaction.BodyJSON)
+		if err != nil {
+			return rest.Response{}, fmt.Errorf("cassette: invalid JSON body on fixture file: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	var err error
+	if interaction.Error != "" {
+		err = errors.New(interaction.Error)
+	}
+	if !isSuccess(interaction.StatusCode) && interaction.StatusCode != 0 {
+		err = rest.StatusError{
+			Method:     interaction.Method,
+			URL:        interaction.URL,
+			StatusCode: interaction.StatusCode,
+			Body:       body,
+		}
+	}
+
+	return rest.Response{
+		Body:       body,
+		Header:     interaction.Header,
+		StatusCode: interaction.StatusCode,
+	}, err
+}
+
+func (c *Client) callNext(ctx context.Context, method string, url string, data rest.RequestData) (rest.Response, error) {
+	switch method {
+	case "POST":
+		return c.next.Post(ctx, url, data)
+	case "PUT":
+		return c.next.Put(ctx, url, data)
+	case "PATCH":
+		return c.next.Patch(ctx, url, data)
+	case "DELETE":
+		return c.next.Delete(ctx, url, data)
+	default:
+		return c.next.Get(ctx, url, data)
+	}
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+func containsRequest(interactions []Interaction, target Interaction) bool {
+	for _, interaction := range interactions {
+		if interaction.Method == target.Method &&
+			interaction.URL == target.URL &&
+			interaction.RequestBody == target.RequestBody {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubURL replaces the values of all sensitive query params and also
+// sorts the query params so the URL can be used for matching requests.
+//
+// It also returns the original values of the scrubbed params, so they
+// can also be removed from other places, e.g. error messages.
+func (c *Client) scrubURL(rawURL string) (scrubbedURL string, secrets []string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, nil
+	}
+
+	query := u.Query()
+	for _, param := range c.config.ScrubQueryParams {
+		values, ok := query[param]
+		if !ok {
+			continue
+		}
+
+		secrets = append(secrets, values...)
+		query.Set(param, Redacted)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), secrets
+}
+
+// scrubBody replaces the values of the sensitive fields of JSON and
+// form-encoded bodies, other bodies are returned unchanged.
+//
+// Like scrubURL it also returns the original values of the scrubbed fields.
+func (c *Client) scrubBody(body string) (scrubbedBody string, secrets []string) {
+	if body == "" {
+		return body, nil
+	}
+
+	if json.Valid([]byte(body)) {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+
+		var value interface{}
+		if decoder.Decode(&value) != nil {
+			return body, nil
+		}
+
+		value, secrets = c.scrubJSON(value)
+		if len(secrets) == 0 {
+			return body, nil
+		}
+
+		rawBody, err := json.Marshal(value)
+		if err != nil {
+			return body, nil
+		}
+		return string(rawBody), secrets
+	}
+
+	form, err := url.ParseQuery(body)
+	if err != nil {
+		return body, nil
+	}
+	for _, param := range c.config.ScrubQueryParams {
+		values, ok := form[param]
+		if !ok {
+			continue
+		}
+
+		secrets = append(secrets, values...)
+		form.Set(param, Redacted)
+	}
+	if len(secrets) == 0 {
+		return body, nil
+	}
+	return form.Encode(), secrets
+}
+
+// scrubJSON replaces the values of the sensitive fields on any level of the
+// input JSON value, as decoded by encoding/json, returning the scrubbed values.
+func (c *Client) scrubJSON(value interface{}) (_ interface{}, secrets []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if c.isScrubbedParam(key) {
+				secrets = append(secrets, jsonStrings(field)...)
+				v[key] = Redacted
+				continue
+			}
+
+			var fieldSecrets []string
+			v[key], fieldSecrets = c.scrubJSON(field)
+			secrets = append(secrets, fieldSecrets...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			var itemSecrets []string
+			v[i], itemSecrets = c.scrubJSON(item)
+			secrets = append(secrets, itemSecrets...)
+		}
+	}
+	return value, secrets
+}
+
+func (c *Client) isScrubbedParam(name string) bool {
+	for _, param := range c.config.ScrubQueryParams {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonStrings returns all the strings and numbers contained in a decoded JSON value
+func jsonStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case json.Number:
+		return []string{v.String()}
+	case map[string]interface{}:
+		var values []string
+		for _, field := range v {
+			values = append(values, jsonStrings(field)...)
+		}
+		return values
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, jsonStrings(item)...)
+		}
+		return values
+	}
+	return nil
+}
+
+func (c *Client) scrubHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	scrubbed := header.Clone()
+	for _, sensitive := range c.config.ScrubHeaders {
+		if _, ok := scrubbed[http.CanonicalHeaderKey(sensitive)]; ok {
+			scrubbed.Set(sensitive, Redacted)
+		}
+	}
+	return scrubbed
+}
+
+func scrubString(s string, secrets []string) string {
+	// Replacing the longest secrets first avoids leaving
+	// parts of a secret behind when one contains the other:
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, Redacted)
+		s = strings.ReplaceAll(s, url.QueryEscape(secret), Redacted)
+	}
+	return s
+}
+
+// readBody returns the request body as a string so it can be compared,
+// if the body is an io.Reader it is replaced by a new reader with the same
+// content so the decorated provider can still read it.
+func readBody(data *rest.RequestData) (string, error) {
+	switch body := data.Body.(type) {
+	case nil:
+		return "", nil
+	case io.Reader:
+		rawBody, err := io.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		data.Body = bytes.NewReader(rawBody)
+		return string(rawBody), nil
+	case []byte:
+		return string(body), nil
+	case string:
+		return body, nil
+	case rest.Form:
+		return url.Values(body).Encode(), nil
+	default:
+		// Multipart bodies are also marshaled as JSON since
+		// their encoding uses a random boundary on each request:
+		rawBody, err := json.Marshal(body)
+		return string(rawBody), err
+	}
+}
@@ -0,0 +1,154 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should replay recorded interactions with all secrets scrubbed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.json")
+
+		var calls int
+		recorder, err := New(rest.Mock{
+			GetFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+				calls++
+				if calls == 2 {
+					return rest.Response{
+						StatusCode: 404,
+						Body:       []byte("not found"),
+					}, fmt.Errorf("GET %s: unexpected status code: 404", url)
+				}
+				return rest.Response{
+					StatusCode: 200,
+					Header: http.Header{
+						"Content-Type": {"application/json"},
+						"Set-Cookie":   {"session=fakeSession", "other=fakeSession"},
+					},
+					Body: []byte(`{"fake":"body"}`),
+				}, nil
+			},
+		}, Config{
+			Mode: ModeRecord,
+			Path: path,
+		})
+		tt.AssertNoErr(t, err)
+
+		_, err = recorder.Get(ctx, "http://fake.host/foo?v=1&client_secret=fakeSecret", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		_, err = recorder.Get(ctx, "http://fake.host/bar?client_secret=fakeSecret", rest.RequestData{})
+		tt.AssertErrContains(t, err, "404")
+		tt.AssertNoErr(t, recorder.Save())
+
+		rawFixture, err := os.ReadFile(path)
+		tt.AssertNoErr(t, err)
+		tt.AssertFalse(t, strings.Contains(string(rawFixture), "fakeSecret"), "secret leaked to the fixture file")
+		tt.AssertFalse(t, strings.Contains(string(rawFixture), "fakeSession"), "cookie leaked to the fixture file")
+
+		player, err := New(nil, Config{
+			Path: path,
+		})
+		tt.AssertNoErr(t, err)
+
+		// The order of the query params and the secret values shouldn't matter:
+		resp, err := player.Get(ctx, "http://fake.host/foo?client_secret=otherSecret&v=1", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, resp.StatusCode, 200)
+		tt.AssertEqual(t, string(resp.Body), `{"fake":"body"}`)
+		tt.AssertEqual(t, resp.Header.Values("Set-Cookie"), []string{Redacted})
+
+		resp, err = player.Get(ctx, "http://fake.host/bar?client_secret=otherSecret", rest.RequestData{})
+		tt.AssertErrContains(t, err, "404", "client_secret="+Redacted)
+		tt.AssertEqual(t, resp.StatusCode, 404)
+		tt.AssertEqual(t, string(resp.Body), "not found")
+
+		_, err = player.Post(ctx, "http://fake.host/foo?v=1", rest.RequestData{})
+		tt.AssertErrContains(t, err, "no recorded interaction", "POST")
+	})
+
+	t.Run("should scrub the secrets sent on the request bodies", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.json")
+
+		var requestBodies []interface{}
+		recorder, err := New(rest.Mock{
+			PostFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+				requestBodies = append(requestBodies, data.Body)
+				return rest.Response{
+					StatusCode: 200,
+					Body:       []byte(`{"access_token":"fakeToken"}`),
+				}, nil
+			},
+		}, Config{
+			Mode: ModeRecord,
+			Path: path,
+		})
+		tt.AssertNoErr(t, err)
+
+		_, err = recorder.Post(ctx, "http://fake.host/token", rest.RequestData{
+			Body: rest.Form{
+				"grant_type":    {"client_credentials"},
+				"client_secret": {"fakeSecret"},
+			},
+		})
+		tt.AssertNoErr(t, err)
+		_, err = recorder.Post(ctx, "http://fake.host/json", rest.RequestData{
+			Body: map[string]interface{}{
+				"user": map[string]interface{}{
+					"name":          "fakeName",
+					"client_secret": "fakeSecret",
+				},
+			},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertNoErr(t, recorder.Save())
+
+		// The upstream must still receive the original secrets:
+		tt.AssertEqual(t, requestBodies[0], rest.Form{
+			"grant_type":    {"client_credentials"},
+			"client_secret": {"fakeSecret"},
+		})
+
+		rawFixture, err := os.ReadFile(path)
+		tt.AssertNoErr(t, err)
+		tt.AssertFalse(t, strings.Contains(string(rawFixture), "fakeSecret"), "secret leaked to the fixture file")
+		tt.AssertContains(t, string(rawFixture), "fakeName")
+
+		player, err := New(nil, Config{
+			Path: path,
+		})
+		tt.AssertNoErr(t, err)
+
+		resp, err := player.Post(ctx, "http://fake.host/token", rest.RequestData{
+			Body: rest.Form{
+				"grant_type":    {"client_credentials"},
+				"client_secret": {"otherSecret"},
+			},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, resp.StatusCode, 200)
+
+		resp, err = player.Post(ctx, "http://fake.host/json", rest.RequestData{
+			Body: `{"user":{"client_secret":"otherSecret","name":"fakeName"}}`,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, resp.StatusCode, 200)
+
+		_, err = player.Post(ctx, "http://fake.host/token", rest.RequestData{
+			Body: rest.Form{
+				"grant_type":    {"password"},
+				"client_secret": {"fakeSecret"},
+			},
+		})
+		tt.AssertErrContains(t, err, "no recorded interaction")
+	})
+}
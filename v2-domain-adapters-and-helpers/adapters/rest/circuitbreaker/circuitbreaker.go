@@ -0,0 +1,330 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Client is a decorator for any rest.Provider that keeps one circuit breaker
+// per destination host, so when a host is failing we stop waiting for
+// its timeouts and fail fast with a domain.UnavailableErr instead.
+//
+// Each breaker works as follows:
+//
+//   - closed: requests go through and the results are counted, if the failure
+//     rate on the current window goes above the threshold the breaker opens;
+//   - open: requests fail immediately until the cooldown has passed,
+//     then the breaker becomes half-open;
+//   - half-open: a few trial requests go through, if they all succeed
+//     the breaker closes again, if any of them fail it opens again.
+type Client struct {
+	next   rest.Provider
+	config Config
+	now    func() time.Time
+
+	mutex    sync.Mutex
+	breakers map[string]*breaker
+}
+
+// Config describes the optional configurations of the circuit breaker
+type Config struct {
+	// FailureRateThreshold is the fraction of failed requests (from 0 to 1)
+	// that opens the breaker. Defaults to 0.5.
+	FailureRateThreshold float64
+
+	// MinRequests is the minimum number of requests on the current window
+	// before the failure rate is evaluated. Defaults to 10.
+	MinRequests int
+
+	// Window is how long the failures are counted before the
+	// counters are reset. Defaults to 1 minute.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before
+	// allowing trial requests. Defaults to 30 seconds.
+	Cooldown time.Duration
+
+	// HalfOpenMaxRequests is the number of trial requests that need
+	// to succeed before closing the breaker again. Defaults to 1.
+	HalfOpenMaxRequests int
+
+	// BaseURL should match the BaseURL of the decorated http client,
+	// it is used for finding the host of relative URLs. Without it
+	// relative URLs are rejected, since they share no host.
+	BaseURL string
+
+	// Logger is used for reporting state changes and is optional
+	Logger log.Provider
+}
+
+type state string
+
+const (
+	closed   state = "closed"
+	open     state = "open"
+	halfOpen state = "half-open"
+)
+
+type breaker struct {
+	state       state
+	windowStart time.Time
+	openedAt    time.Time
+	successes   int
+	failures    int
+
+	// Number of trial requests sent while half-open:
+	trials int
+}
+
+// New instantiates a new circuit breaker decorating the input rest.Provider
+func New(next rest.Provider, config Config) *Client {
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+
+	return &Client{
+		next:     next,
+		config:   config,
+		now:      time.Now,
+		breakers: map[string]*breaker{},
+	}
+}
+
+// Get implements the rest.Provider interface
+func (c *Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.call(ctx, url, func() (rest.Response, error) {
+		return c.next.Get(ctx, url, data)
+	})
+}
+
+// Post implements the rest.Provider interface
+func (c *Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.call(ctx, url, func() (rest.Response, error) {
+		return c.next.Post(ctx, url, data)
+	})
+}
+
+// Put implements the rest.Provider interface
+func (c *Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.call(ctx, url, func() (rest.Response, error) {
+		return c.next.Put(ctx, url, data)
+	})
+}
+
+// Patch implements the rest.Provider interface
+func (c *Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.call(ctx, url, func() (rest.Response, error) {
+		return c.next.Patch(ctx, url, data)
+	})
+}
+
+// Delete implements the rest.Provider interface
+func (c *Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.call(ctx, url, func() (rest.Response, error) {
+		return c.next.Delete(ctx, url, data)
+	})
+}
+
+// Stream implements the rest.Streamer interface
+func (c *Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	var streamResp rest.StreamResponse
+	_, err := c.call(ctx, url, func() (rest.Response, error) {
+		var err error
+		streamResp, err = rest.Stream(ctx, c.next, method, url, data)
+		return rest.Response{
+			Header:     streamResp.Header,
+			StatusCode: streamResp.StatusCode,
+		}, err
+	})
+	return streamResp, err
+}
+
+func (c *Client) call(ctx context.Context, rawURL string, fn func() (rest.Response, error)) (rest.Response, error) {
+	host := hostOf(rawURL, c.config.BaseURL)
+	if host == "" {
+		return rest.Response{}, domain.InternalErr("circuit-breaker-unable-to-find-url-host", map[string]interface{}{
+			"url":      rest.RedactURL(rawURL),
+			"base_url": c.config.BaseURL,
+		})
+	}
+
+	retryIn, allowed := c.allow(ctx, host)
+	if !allowed {
+		return rest.Response{}, domain.UnavailableErr("upstream-service-unavailable", map[string]interface{}{
+			"host":        host,
+			"retry_in_ms": retryIn.Milliseconds(),
+		})
+	}
+
+	resp, err := fn()
+
+	// Requests canceled by the caller say nothing about the health of the host:
+	if ctx.Err() != nil {
+		c.release(host)
+		return resp, err
+	}
+
+	c.record(ctx, host, isFailure(resp, err))
+	return resp, err
+}
+
+// allow checks if a request to the input host should be sent,
+// if not it returns how long until the next trial request.
+func (c *Client) allow(ctx context.Context, host string) (retryIn time.Duration, allowed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	b := c.breakerFor(host, now)
+
+	switch b.state {
+	case open:
+		elapsed := now.Sub(b.openedAt)
+		if elapsed < c.config.Cooldown {
+			return c.config.Cooldown - elapsed, false
+		}
+
+		c.setState(ctx, host, b, halfOpen, now)
+		fallthrough
+
+	case halfOpen:
+		if b.trials >= c.config.HalfOpenMaxRequests {
+			return 0, false
+		}
+		b.trials++
+		return 0, true
+	}
+
+	if now.Sub(b.windowStart) >= c.config.Window {
+		b.windowStart = now
+		b.successes = 0
+		b.failures = 0
+	}
+	return 0, true
+}
+
+// release gives back a half-open trial slot when the result
+// of the request shouldn't be counted.
+func (c *Client) release(host string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	b := c.breakers[host]
+	if b.state == halfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+func (c *Client) record(ctx context.Context, host string, failed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	b := c.breakerFor(host, now)
+
+	if failed {
+		b.failures++
+	} else {
+		b.successes++
+	}
+
+	switch b.state {
+	case halfOpen:
+		if failed {
+			c.setState(ctx, host, b, open, now)
+		} else if b.successes >= c.config.HalfOpenMaxRequests {
+			c.setState(ctx, host, b, closed, now)
+		}
+
+	case closed:
+		total := b.successes + b.failures
+		if total < c.config.MinRequests {
+			return
+		}
+
+		if float64(b.failures)/float64(total) >= c.config.FailureRateThreshold {
+			c.setState(ctx, host, b, open, now)
+		}
+	}
+}
+
+func (c *Client) breakerFor(host string, now time.Time) *breaker {
+	b, ok := c.breakers[host]
+	if !ok {
+		b = &breaker{
+			state:       closed,
+			windowStart: now,
+		}
+		c.breakers[host] = b
+	}
+	return b
+}
+
+func (c *Client) setState(ctx context.Context, host string, b *breaker, newState state, now time.Time) {
+	if c.config.Logger != nil {
+		c.config.Logger.Warn(ctx, "circuit-breaker-state-changed", log.Body{
+			"host":      host,
+			"from":      string(b.state),
+			"to":        string(newState),
+			"failures":  b.failures,
+			"successes": b.successes,
+		})
+	}
+
+	b.state = newState
+	b.windowStart = now
+	b.openedAt = now
+	b.successes = 0
+	b.failures = 0
+	b.trials = 0
+}
+
+// isFailure considers network errors and 5xx responses as failures,
+// 4xx responses mean the host is working, so they are not counted.
+func isFailure(resp rest.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Requests rejected by our own rate limiter never reached the host:
+	if resp.StatusCode == 0 && domain.AsDomainErr(err).Code == "RateLimitedErr" {
+		return false
+	}
+	return resp.StatusCode == 0 || resp.StatusCode >= 500
+}
+
+// hostOf returns the host the request is sent to, relative URLs
+// are resolved with the base URL, the same way the http client does.
+func hostOf(rawURL string, baseURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if u.Host != "" {
+		return u.Host
+	}
+
+	u, err = url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,188 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	var statusCode int
+	var calls int
+	mock := rest.Mock{
+		GetFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+			calls++
+			if statusCode >= 400 {
+				err = fmt.Errorf("fake error with status %d", statusCode)
+			}
+			return rest.Response{StatusCode: statusCode}, err
+		},
+		StreamFn: func(ctx context.Context, method string, url string, data rest.RequestData) (resp rest.StreamResponse, err error) {
+			calls++
+			if statusCode >= 400 {
+				err = fmt.Errorf("fake error with status %d", statusCode)
+			}
+			return rest.StreamResponse{StatusCode: statusCode}, err
+		},
+	}
+
+	now := time.Date(2021, 5, 14, 10, 0, 0, 0, time.UTC)
+	newClient := func() *Client {
+		calls = 0
+		client := New(mock, Config{
+			FailureRateThreshold: 0.5,
+			MinRequests:          4,
+			Window:               time.Minute,
+			Cooldown:             10 * time.Second,
+		})
+		client.now = func() time.Time { return now }
+		return client
+	}
+
+	t.Run("should open after the failure rate threshold is reached", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 200
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+		}
+
+		statusCode = 503
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertErrContains(t, err, "503")
+		}
+		tt.AssertEqual(t, calls, 4)
+
+		_, err := client.Get(ctx, "http://fake.host/bar", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+		tt.AssertEqual(t, calls, 4)
+
+		// Other hosts should not be affected:
+		statusCode = 200
+		_, err = client.Get(ctx, "http://other.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, calls, 5)
+	})
+
+	t.Run("should not count 4xx responses as failures", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 404
+		for i := 0; i < 10; i++ {
+			_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertErrContains(t, err, "404")
+		}
+		tt.AssertEqual(t, calls, 10)
+	})
+
+	t.Run("should close again after a successful trial request", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 503
+		for i := 0; i < 4; i++ {
+			client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		}
+
+		_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+
+		now = now.Add(11 * time.Second)
+
+		// A failed trial request should open it again:
+		_, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertErrContains(t, err, "503")
+		_, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+		tt.AssertEqual(t, calls, 5)
+
+		now = now.Add(11 * time.Second)
+
+		statusCode = 200
+		for i := 0; i < 3; i++ {
+			_, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+		}
+		tt.AssertEqual(t, calls, 8)
+	})
+
+	t.Run("should reset the counters when the window expires", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 503
+		for i := 0; i < 3; i++ {
+			client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		}
+
+		now = now.Add(2 * time.Minute)
+
+		statusCode = 200
+		_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+
+		statusCode = 503
+		_, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertErrContains(t, err, "503")
+		tt.AssertEqual(t, calls, 5)
+	})
+
+	t.Run("should also count the streamed requests", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 503
+		for i := 0; i < 4; i++ {
+			_, err := client.Stream(ctx, "GET", "http://fake.host/foo", rest.RequestData{})
+			tt.AssertErrContains(t, err, "503")
+		}
+		tt.AssertEqual(t, calls, 4)
+
+		_, err := client.Stream(ctx, "GET", "http://fake.host/foo", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+		tt.AssertEqual(t, calls, 4)
+	})
+
+	t.Run("should use the host of the base URL for relative URLs", func(t *testing.T) {
+		calls = 0
+		client := New(mock, Config{
+			FailureRateThreshold: 0.5,
+			MinRequests:          4,
+			Window:               time.Minute,
+			Cooldown:             10 * time.Second,
+			BaseURL:              "http://fake.host/v2",
+		})
+		client.now = func() time.Time { return now }
+
+		statusCode = 503
+		for i := 0; i < 4; i++ {
+			_, err := client.Get(ctx, "/foo", rest.RequestData{})
+			tt.AssertErrContains(t, err, "503")
+		}
+
+		_, err := client.Get(ctx, "http://fake.host/bar", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+
+		statusCode = 200
+		_, err = client.Get(ctx, "http://other.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, calls, 5)
+	})
+
+	t.Run("should reject relative URLs without a base URL", func(t *testing.T) {
+		client := newClient()
+
+		statusCode = 200
+		_, err := client.Get(ctx, "/foo?client_secret=fakeSecret", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "InternalErr")
+		tt.AssertFalse(t, strings.Contains(err.Error(), "fakeSecret"), "secret leaked to the error")
+		tt.AssertEqual(t, calls, 0)
+	})
+}
@@ -1,15 +1,23 @@
 package rest
 
-import "context"
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
 
-// Provider provides the functions to perform
-// REST requests automatically marshalling the input body as JSON.
+// Provider provides the functions to perform REST requests
+// encoding the input body according to its type, see `RequestData`.
 //
-// It returns error only if it was not possible to complete the request
-// either because of a marshal error or a network error.
+// Responses with a status code outside of the 2xx range return a
+// `StatusError` together with the response, so the caller can still
+// read its body. Errors are also returned when it was not possible to
+// complete the request, e.g. on encoding or network errors, and when the
+// response body is larger than the configured limit, see `ResponseTooLargeError`.
 //
-// Otherwise the statusCode should be used to check if the request
-// was processed successfully.
+// Large payloads can be read without loading them into memory
+// with the providers that also implement the `Streamer` interface.
 type Provider interface {
 	Get(ctx context.Context, url string, data RequestData) (resp Response, err error)
 	Post(ctx context.Context, url string, data RequestData) (resp Response, err error)
@@ -21,17 +29,37 @@ type Provider interface {
 // RequestData describes the optional arguments for all
 // the http methods of this client.
 type RequestData struct {
-	// The body accepts any struct that can
-	// be marshaled into JSON
+	// Body accepts nil, []byte, string, io.Reader, Form, Multipart
+	// or any value that can be marshaled into JSON, see `EncodeBody`
+	// for how each of them is sent.
 	Body interface{}
 
 	Headers map[string]string
+
+	// Query params are properly encoded and appended
+	// to any query params already present on the URL
+	Query url.Values
+}
+
+// Streamer is implemented by the providers that can return the
+// response body as a stream instead of reading it into memory,
+// which is useful for large payloads such as files.
+type Streamer interface {
+	Stream(ctx context.Context, method string, url string, data RequestData) (StreamResponse, error)
 }
 
 // Response describes the expected attributes
 // on the response for a REST request
 type Response struct {
 	Body       []byte
-	Header     map[string]string
+	Header     http.Header
+	StatusCode int
+}
+
+// StreamResponse is the response returned by a Streamer,
+// the Body must always be closed by the caller.
+type StreamResponse struct {
+	Body       io.ReadCloser
+	Header     http.Header
 	StatusCode int
 }
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// StatusError is returned by the rest providers whenever
+// the response has a status code outside of the 2xx range.
+type StatusError struct {
+	Method string
+
+	// URL is the request URL with all sensitive query params redacted,
+	// so it is always safe to log it.
+	URL string
+
+	StatusCode int
+	Body       []byte
+}
+
+// NewStatusError builds a StatusError redacting any sensitive
+// query params from the input URL
+func NewStatusError(method string, rawURL string, statusCode int, body []byte) StatusError {
+	return StatusError{
+		Method:     method,
+		URL:        RedactURL(rawURL),
+		StatusCode: statusCode,
+		Body:       body,
+	}
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf(
+		"%s %s: unexpected status code: %d, payload: %s",
+		e.Method, e.URL, e.StatusCode, string(e.Body),
+	)
+}
+
+// DomainErr converts the StatusError into the most appropriate domain error,
+//
+// Note that most 4xx errors are converted into internal errors because if the
+// upstream service rejected our request it means we have a bug, not our users.
+//
+// The data of the other errors is sent to our clients, so the details
+// of the upstream request are only kept on the internal errors, which
+// are just logged, and on the message returned by Error().
+func (e StatusError) DomainErr() domain.DomainErr {
+	switch e.StatusCode {
+	case 404:
+		return domain.NotFoundErr("upstream-resource-not-found", nil)
+	case 429:
+		return domain.RateLimitedErr("rate-limited-by-upstream-service", nil)
+	case 502, 503, 504:
+		return domain.UnavailableErr("upstream-service-unavailable", nil)
+	default:
+		return domain.InternalErr("unexpected-upstream-status-code", map[string]interface{}{
+			"method":      e.Method,
+			"url":         e.URL,
+			"status_code": e.StatusCode,
+			"payload":     string(e.Body),
+		})
+	}
+}
+
+// ResponseTooLargeError is returned when the response body
+// is larger than the maximum size allowed by the provider.
+type ResponseTooLargeError struct {
+	Method string
+
+	// URL is the request URL with all sensitive query params redacted
+	URL string
+
+	MaxSize int64
+}
+
+func (e ResponseTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"%s %s: response body is larger than the maximum allowed size of %d bytes",
+		e.Method, e.URL, e.MaxSize,
+	)
+}
+
+// DomainErr implements the domain.DomainErrConverter interface
+func (e ResponseTooLargeError) DomainErr() domain.DomainErr {
+	return domain.InternalErr("upstream-response-too-large", map[string]interface{}{
+		"method":   e.Method,
+		"url":      e.URL,
+		"max_size": e.MaxSize,
+	})
+}
+
+// Redacted is the value used for replacing sensitive information
+const Redacted = "REDACTED"
+
+// SensitiveQueryParams lists the query params that are redacted
+// from URLs before they are logged or added to error messages.
+var SensitiveQueryParams = []string{
+	"client_id",
+	"client_secret",
+	"access_token",
+	"api_key",
+}
+
+// RedactURL replaces the values of all sensitive query params of the input URL,
+// the extraParams argument allows redacting other params besides the ones
+// listed on SensitiveQueryParams.
+func RedactURL(rawURL string, extraParams ...string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// We can't know what is sensitive here so we hide everything:
+		return Redacted
+	}
+
+	query := u.Query()
+	changed := false
+	for _, params := range [][]string{SensitiveQueryParams, extraParams} {
+		for _, param := range params {
+			if _, ok := query[param]; ok {
+				query.Set(param, Redacted)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// AppendQuery encodes the input query params and appends them
+// to any query params already present on the input URL.
+func AppendQuery(rawURL string, query url.Values) (string, error) {
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	values := u.Query()
+	for k, vs := range query {
+		for _, v := range vs {
+			values.Add(k, v)
+		}
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
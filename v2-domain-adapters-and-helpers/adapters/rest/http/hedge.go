@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+// HedgePolicy describes when a second identical request should be sent
+// while the first one is still running, which trades some extra load on
+// the upstream for a much lower tail latency.
+//
+// Only GET and HEAD requests are hedged. The first successful response is
+// used and the other request is canceled.
+//
+// The hedged request shares the attempts of the RetryPolicy with the first
+// one, so hedging never sends more requests than MaxAttempts, except when
+// retries are disabled, in which case up to two requests are sent.
+//
+// The zero value disables hedging.
+type HedgePolicy struct {
+	// Delay is how long to wait for the first response before
+	// sending the hedged request.
+	//
+	// When Percentile is set, Delay is only used until enough
+	// latencies were observed for the host.
+	Delay time.Duration
+
+	// Percentile enables using the observed latencies of each host
+	// as the delay, e.g. 0.95 sends the hedged request once the first
+	// one is slower than 95% of the recent requests to the same host.
+	Percentile float64
+
+	// MinSamples is how many latencies must be observed for a host
+	// before Percentile is used. Defaults to 20.
+	MinSamples int
+}
+
+func (p HedgePolicy) withDefaults() HedgePolicy {
+	if p.MinSamples <= 0 {
+		p.MinSamples = 20
+	}
+	return p
+}
+
+func (p HedgePolicy) enabled() bool {
+	return p.Delay > 0 || p.Percentile > 0
+}
+
+// delayFor returns how long to wait before hedging a request to the
+// input host, it returns false if the request should not be hedged.
+func (p HedgePolicy) delayFor(host string, latencies *latencyTracker) (time.Duration, bool) {
+	if p.Percentile > 0 {
+		delay, ok := latencies.percentile(host, p.Percentile, p.MinSamples)
+		if ok {
+			return delay, true
+		}
+	}
+
+	return p.Delay, p.Delay > 0
+}
+
+type hedgeResult struct {
+	resp rest.Response
+	err  error
+}
+
+// hedge sends the request and, if it takes longer than the hedging delay,
+// a second identical one, returning the first successful response.
+func (c Client) hedge(ctx context.Context, method string, url string, data rest.RequestData) (rest.Response, error) {
+	host := hostOf(url, c.baseURL)
+	delay, ok := c.hedging.delayFor(host, c.latencies)
+
+	// Readers can't be shared between both requests:
+	_, isReader := data.Body.(io.Reader)
+	if !ok || isReader {
+		return c.request(ctx, method, url, data, nil)
+	}
+
+	attempts := newAttemptBudget(max(c.retry.maxAttemptsFor(method), 2))
+
+	// Canceling the context on return stops the request that lost the race:
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	startTime := time.Now()
+	results := make(chan hedgeResult, 2)
+	send := func() {
+		go func() {
+			resp, err := c.request(ctx, method, url, data, attempts)
+			results <- hedgeResult{resp: resp, err: err}
+		}()
+	}
+
+	send()
+	pending := 1
+
+	// The error of a request that was not sent is never returned
+	// if the other one was sent and failed:
+	var failure *hedgeResult
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			// The first request might have used all the attempts retrying:
+			if attempts.left() == 0 {
+				continue
+			}
+
+			if c.logger != nil {
+				c.logger.Debug(ctx, "sending-hedged-rest-request", log.Body{
+					"method":   method,
+					"url":      c.redactURL(url),
+					"delay_ms": delay.Milliseconds(),
+				})
+			}
+			send()
+			pending++
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				c.latencies.observe(host, time.Since(startTime))
+				return result.resp, nil
+			}
+
+			if failure == nil || result.err != errNoAttemptsLeft {
+				failure = &result
+			}
+
+			// If the hedged request was not sent yet there is
+			// nothing to wait for, otherwise we wait for the other one:
+			if pending == 0 {
+				return failure.resp, failure.err
+			}
+		}
+	}
+}
+
+// latencyTracker keeps the latest latencies of each host
+type latencyTracker struct {
+	mutex  sync.Mutex
+	byHost map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer with the latest latencies of a host
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+const latencyWindowSize = 100
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		byHost: map[string]*latencyWindow{},
+	}
+}
+
+func (l *latencyTracker) observe(host string, latency time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	w, ok := l.byHost[host]
+	if !ok {
+		w = &latencyWindow{}
+		l.byHost[host] = w
+	}
+
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+func (l *latencyTracker) percentile(host string, percentile float64, minSamples int) (time.Duration, bool) {
+	l.mutex.Lock()
+	w, ok := l.byHost[host]
+	if !ok || len(w.samples) < minSamples {
+		l.mutex.Unlock()
+		return 0, false
+	}
+	samples := append([]time.Duration(nil), w.samples...)
+	l.mutex.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	i := int(percentile * float64(len(samples)))
+	if i >= len(samples) {
+		i = len(samples) - 1
+	}
+	return samples[i], true
+}
+
+// hostOf returns the host the request will be sent to, taking
+// into account that relative URLs are sent to the base URL.
+func hostOf(rawURL string, baseURL string) string {
+	u, err := url.Parse(rawURL)
+	if err == nil && u.Host != "" {
+		return u.Host
+	}
+
+	u, err = url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestHedging(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	var canceled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/slow-first" && call == 1 {
+			select {
+			case <-r.Context().Done():
+				atomic.AddInt32(&canceled, 1)
+				return
+			case <-time.After(time.Second):
+			}
+		}
+		w.Write([]byte("response"))
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		Timeout: 2 * time.Second,
+		Hedge: HedgePolicy{
+			Delay: 50 * time.Millisecond,
+		},
+	})
+
+	t.Run("should use the hedged response when the first one is slow", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+
+		startTime := time.Now()
+		resp, err := client.Get(ctx, server.URL+"/slow-first", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, string(resp.Body), "response")
+		tt.AssertApproxDuration(t, 100*time.Millisecond, time.Since(startTime), 50*time.Millisecond, "unexpected request duration")
+		tt.AssertEqual(t, atomic.LoadInt32(&calls), int32(2))
+
+		// The slow request should be canceled:
+		time.Sleep(50 * time.Millisecond)
+		tt.AssertEqual(t, atomic.LoadInt32(&canceled), int32(1))
+	})
+
+	t.Run("should not hedge fast requests", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+
+		_, err := client.Get(ctx, server.URL+"/fast", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+		tt.AssertEqual(t, atomic.LoadInt32(&calls), int32(1))
+	})
+
+	t.Run("should not hedge non idempotent requests", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+
+		_, err := client.Post(ctx, server.URL+"/slow-first", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, atomic.LoadInt32(&calls), int32(1))
+	})
+
+	t.Run("should share the retry attempts with the hedged request", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewWithConfig(Config{
+			Timeout: 2 * time.Second,
+			Retry: RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+			},
+			Hedge: HedgePolicy{
+				Delay: 10 * time.Millisecond,
+			},
+		})
+
+		_, err := client.Get(ctx, server.URL+"/unavailable", rest.RequestData{})
+		tt.AssertErrContains(t, err, "503")
+
+		time.Sleep(50 * time.Millisecond)
+		tt.AssertEqual(t, atomic.LoadInt32(&calls), int32(3))
+	})
+}
+
+func TestHedgeDelay(t *testing.T) {
+	policy := HedgePolicy{
+		Delay:      time.Second,
+		Percentile: 0.95,
+		MinSamples: 10,
+	}.withDefaults()
+	latencies := newLatencyTracker()
+
+	t.Run("should use the fixed delay until enough latencies were observed", func(t *testing.T) {
+		for i := 1; i < 10; i++ {
+			latencies.observe("fake.host", time.Duration(i)*time.Millisecond)
+		}
+
+		delay, ok := policy.delayFor("fake.host", latencies)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, delay, time.Second)
+	})
+
+	t.Run("should use the observed percentile of the host", func(t *testing.T) {
+		for i := 10; i <= 200; i++ {
+			latencies.observe("fake.host", time.Duration(i)*time.Millisecond)
+		}
+
+		// Only the last 100 latencies are kept, i.e. from 101ms to 200ms:
+		delay, ok := policy.delayFor("fake.host", latencies)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, delay, 196*time.Millisecond)
+
+		delay, ok = policy.delayFor("other.host", latencies)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, delay, time.Second)
+	})
+}
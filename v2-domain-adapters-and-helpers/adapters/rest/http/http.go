@@ -3,13 +3,11 @@ package http
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
 )
 
@@ -17,16 +15,100 @@ import (
 // these methods accept any struct that can be marshaled into JSON
 // but the response is returned in Bytes, since not all APIs follow
 // rest strictly.
+//
+// Responses with status codes outside of the 2xx range are
+// returned along with a `rest.StatusError`.
 type Client struct {
-	http http.Client
+	http   http.Client
+	retry  RetryPolicy
+	logger log.Provider
+
+	propagation       Propagation
+	propagationByHost map[string]Propagation
+
+	baseURL              string
+	sensitiveQueryParams []string
+
+	maxResponseSize int64
+
+	hedging   HedgePolicy
+	latencies *latencyTracker
+}
+
+// Config describes the optional configurations of the Client
+type Config struct {
+	// Timeout limits the time spent on each attempt of a request
+	Timeout time.Duration
+
+	// Transport is used for sending the requests, use NewTransport for
+	// configuring proxies, certificates and the connection pool.
+	// Defaults to `http.DefaultTransport`.
+	Transport http.RoundTripper
+
+	// Retry describes how failed requests should be retried,
+	// the zero value disables retries.
+	Retry RetryPolicy
+
+	// Logger is used for reporting retries and is optional
+	Logger log.Provider
+
+	// Propagation describes which values from the context are
+	// forwarded as headers to all destinations, except the ones
+	// that have a specific rule on PropagationByHost.
+	Propagation Propagation
+
+	// PropagationByHost allows using different rules for each destination,
+	// the keys are host names with or without the port, e.g. "api.foursquare.com".
+	PropagationByHost map[string]Propagation
+
+	// BaseURL is prepended to all URLs that don't start with a scheme,
+	// e.g. with a BaseURL of "https://api.foursquare.com/v2" a request
+	// to "/venues/search" is sent to "https://api.foursquare.com/v2/venues/search".
+	BaseURL string
+
+	// SensitiveQueryParams lists query params that should be redacted from
+	// all logs and errors, besides the ones listed on `rest.SensitiveQueryParams`.
+	SensitiveQueryParams []string
+
+	// MaxResponseSize is the maximum number of bytes read from a
+	// response body, larger responses return a `rest.ResponseTooLargeError`.
+	// Defaults to zero, meaning no limits.
+	MaxResponseSize int64
+
+	// Hedge describes when GET requests should be hedged,
+	// i.e. sent twice for reducing the tail latency.
+	// The zero value disables hedging.
+	Hedge HedgePolicy
 }
 
 // New instantiates a new http client
 func New(timeout time.Duration) Client {
+	return NewWithConfig(Config{
+		Timeout: timeout,
+	})
+}
+
+// NewWithConfig instantiates a new http client with the optional
+// configurations described on the Config struct
+func NewWithConfig(config Config) Client {
 	return Client{
-		http.Client{
-			Timeout: timeout,
+		http: http.Client{
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
 		},
+		retry:  config.Retry.withDefaults(),
+		logger: config.Logger,
+
+		propagation:       config.Propagation,
+		propagationByHost: config.PropagationByHost,
+
+		baseURL:              config.BaseURL,
+		sensitiveQueryParams: config.SensitiveQueryParams,
+
+		maxResponseSize: config.MaxResponseSize,
+
+		hedging:   config.Hedge.withDefaults(),
+		latencies: newLatencyTracker(),
 	}
 }
 
@@ -60,68 +142,284 @@ func (c Client) Delete(ctx context.Context, url string, data rest.RequestData) (
 	return c.makeRequest(ctx, "DELETE", url, data)
 }
 
+// Stream will make a request to the input URL returning
+// the response body as an io.ReadCloser instead of reading it
+// into memory, the caller is responsible for closing it.
+//
+// Note that the Timeout configured on the client also
+// limits the time spent reading the response body.
+func (c Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	resp, errResp, err := c.do(ctx, method, url, data, nil)
+	if err != nil {
+		return rest.StreamResponse{
+			Body:       io.NopCloser(bytes.NewReader(errResp.Body)),
+			Header:     errResp.Header,
+			StatusCode: errResp.StatusCode,
+		}, err
+	}
+
+	return rest.StreamResponse{
+		Body:       c.limitBody(method, url, resp.Body),
+		Header:     resp.Header,
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
 func (c Client) makeRequest(
 	ctx context.Context,
 	method string,
 	url string,
 	data rest.RequestData,
-) (_ rest.Response, err error) {
-	var requestBody io.Reader
-	switch body := data.Body.(type) {
-	case nil:
-		requestBody = nil
-	case io.Reader:
-		requestBody = body
-	case []byte:
-		requestBody = bytes.NewReader(body)
-	case string:
-		requestBody = strings.NewReader(body)
-	default:
-		inputBodyJSON, err := json.Marshal(data.Body)
+) (rest.Response, error) {
+	if c.hedging.enabled() && (method == "GET" || method == "HEAD") {
+		return c.hedge(ctx, method, url, data)
+	}
+
+	return c.request(ctx, method, url, data, nil)
+}
+
+// request sends the request and reads the response body,
+// the attempts budget is optional, see `do` for details.
+func (c Client) request(
+	ctx context.Context,
+	method string,
+	url string,
+	data rest.RequestData,
+	attempts *attemptBudget,
+) (rest.Response, error) {
+	resp, errResp, err := c.do(ctx, method, url, data, attempts)
+	if err != nil {
+		return errResp, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(c.limitBody(method, url, resp.Body))
+	return rest.Response{
+		Body:       body,
+		Header:     resp.Header,
+		StatusCode: resp.StatusCode,
+	}, err
+}
+
+// do sends the request retrying it according to the retry policy,
+// on success it returns the response with its body still open,
+// otherwise it returns the error along with a rest.Response
+// describing the last failed attempt.
+//
+// The attempts budget is shared by all the requests sent for the same
+// call, i.e. the hedged ones, if nil only the retry policy is used.
+func (c Client) do(
+	ctx context.Context,
+	method string,
+	url string,
+	data rest.RequestData,
+	attempts *attemptBudget,
+) (_ *http.Response, errResp rest.Response, _ error) {
+	maxAttempts := c.retry.maxAttemptsFor(method)
+	if attempts == nil {
+		attempts = newAttemptBudget(maxAttempts)
+	}
+
+	url, err := c.buildURL(url, data.Query)
+	if err != nil {
+		return nil, rest.Response{}, err
+	}
+
+	newBody, contentType, err := buildRequestBody(data.Body, maxAttempts > 1)
+	if err != nil {
+		return nil, rest.Response{}, err
+	}
+
+	if !attempts.take() {
+		return nil, rest.Response{}, errNoAttemptsLeft
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, errResp, err := c.send(ctx, method, url, data.Headers, contentType, newBody())
+		if err == nil {
+			return resp, rest.Response{}, nil
+		}
+
+		wait, retry := c.retry.nextWait(ctx, attempt, errResp, err)
+		if attempt < maxAttempts && !retry {
+			return nil, errResp, err
+		}
+
+		// The budget might be exhausted by the other requests of the same call:
+		if attempt >= maxAttempts || !attempts.take() {
+			if attempt > 1 || attempt < maxAttempts {
+				c.log(ctx, "rest-request-retries-exhausted", log.Body{
+					"method":      method,
+					"url":         c.redactURL(url),
+					"attempts":    attempt,
+					"status_code": errResp.StatusCode,
+					"error":       err.Error(),
+				})
+			}
+			return nil, errResp, err
+		}
+
+		c.log(ctx, "retrying-rest-request", log.Body{
+			"method":       method,
+			"url":          c.redactURL(url),
+			"attempt":      attempt,
+			"max_attempts": maxAttempts,
+			"status_code":  errResp.StatusCode,
+			"wait_ms":      wait.Milliseconds(),
+			"error":        err.Error(),
+		})
+
+		if !sleep(ctx, wait) {
+			return nil, errResp, err
+		}
+	}
+}
+
+// buildRequestBody returns a function that builds the request body,
+// so it can be sent more than once when the request is retried,
+// along with the Content-Type that matches the type of the body.
+func buildRequestBody(body interface{}, reusable bool) (func() io.Reader, string, error) {
+	if reader, ok := body.(io.Reader); ok {
+		if !reusable {
+			return func() io.Reader { return reader }, "", nil
+		}
+
+		rawBody, err := io.ReadAll(reader)
 		if err != nil {
-			return rest.Response{}, err
+			return nil, "", err
 		}
-		requestBody = bytes.NewReader(inputBodyJSON)
+		body = rawBody
+	}
+
+	rawBody, contentType, err := rest.EncodeBody(body)
+	if err != nil {
+		return nil, "", err
 	}
+	if rawBody == nil {
+		return func() io.Reader { return nil }, contentType, nil
+	}
+
+	return func() io.Reader {
+		return bytes.NewReader(rawBody)
+	}, contentType, nil
+}
 
+// send makes a single attempt of sending the request,
+// responses outside of the 2xx range are read and closed
+// and returned as a rest.Response along with a rest.StatusError.
+func (c Client) send(
+	ctx context.Context,
+	method string,
+	url string,
+	headers map[string]string,
+	contentType string,
+	requestBody io.Reader,
+) (_ *http.Response, errResp rest.Response, _ error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, requestBody)
 	if err != nil {
-		return rest.Response{}, err
+		return nil, rest.Response{}, c.redactErr(err)
 	}
 
-	for k, v := range data.Headers {
+	// Explicit headers take precedence over the propagated ones:
+	for k, v := range c.propagationHeaders(ctx, url) {
 		req.Header.Set(k, v)
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 
-	var resp *http.Response
-	resp, err = c.http.Do(req)
+	resp, err := c.http.Do(req)
 	if err != nil {
-		return rest.Response{}, err
+		return nil, rest.Response{}, c.redactErr(err)
 	}
 
-	header := map[string]string{}
-	for k, v := range resp.Header {
-		if len(v) == 0 {
-			continue
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if c.maxResponseSize > 0 && resp.ContentLength > c.maxResponseSize {
+			resp.Body.Close()
+			return nil, rest.Response{
+				Header:     resp.Header,
+				StatusCode: resp.StatusCode,
+			}, c.tooLargeErr(method, url)
 		}
-		header[k] = v[0]
+		return resp, rest.Response{}, nil
 	}
+	defer resp.Body.Close()
 
-	isStatusSuccess := (resp.StatusCode >= 200 && resp.StatusCode < 300)
+	errResp = rest.Response{
+		Header:     resp.Header,
+		StatusCode: resp.StatusCode,
+	}
 
-	var body []byte
-	body, err = io.ReadAll(resp.Body)
-	if err == nil && !isStatusSuccess {
-		err = fmt.Errorf(
-			"%s %s: unexpected status code: %d, payload: %s",
-			method, url, resp.StatusCode, string(body),
-		)
+	errResp.Body, err = io.ReadAll(c.limitBody(method, url, resp.Body))
+	if err != nil {
+		return nil, errResp, err
 	}
-	resp.Body.Close()
 
-	return rest.Response{
-		Body:       body,
-		Header:     header,
+	return nil, errResp, rest.StatusError{
+		Method:     method,
+		URL:        c.redactURL(url),
 		StatusCode: resp.StatusCode,
-	}, err
+		Body:       errResp.Body,
+	}
+}
+
+// limitBody wraps the input body so reading more than
+// the configured maximum size returns an error.
+func (c Client) limitBody(method string, url string, body io.ReadCloser) io.ReadCloser {
+	if c.maxResponseSize <= 0 {
+		return body
+	}
+
+	return &limitedBody{
+		body:      body,
+		remaining: c.maxResponseSize,
+		err:       c.tooLargeErr(method, url),
+	}
+}
+
+func (c Client) tooLargeErr(method string, url string) error {
+	return rest.ResponseTooLargeError{
+		Method:  method,
+		URL:     c.redactURL(url),
+		MaxSize: c.maxResponseSize,
+	}
+}
+
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err
+	}
+
+	// Reading one extra byte allows us to tell if the body is
+	// exactly the maximum size or if it is larger than that:
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), l.err
+	}
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
+
+func (c Client) log(ctx context.Context, title string, body log.Body) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Warn(ctx, title, body)
 }
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestResponses(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "first=1")
+		w.Header().Add("Set-Cookie", "second=2")
+		if r.URL.Path == "/not-found" {
+			w.WriteHeader(404)
+		}
+		// Writing in chunks avoids sending the Content-Length header:
+		for i := 0; i < 10; i++ {
+			w.Write([]byte("0123456789"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	t.Run("should keep all values of multi-value headers", func(t *testing.T) {
+		client := New(time.Second)
+
+		resp, err := client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, resp.Header.Values("Set-Cookie"), []string{"first=1", "second=2"})
+		tt.AssertEqual(t, len(resp.Body), 100)
+	})
+
+	t.Run("should fail when the body is larger than the max size", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout:         time.Second,
+			MaxResponseSize: 50,
+		})
+
+		_, err := client.Get(ctx, server.URL+"?client_secret=fakeSecret", rest.RequestData{})
+		tt.AssertErrContains(t, err, "larger than the maximum allowed size of 50 bytes", "client_secret=REDACTED")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "upstream-response-too-large")
+	})
+
+	t.Run("should accept bodies with exactly the max size", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout:         time.Second,
+			MaxResponseSize: 100,
+		})
+
+		resp, err := client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(resp.Body), 100)
+	})
+
+	t.Run("should stream the response body", func(t *testing.T) {
+		client := New(time.Second)
+
+		resp, err := client.Stream(ctx, "GET", server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		defer resp.Body.Close()
+
+		tt.AssertEqual(t, resp.StatusCode, 200)
+		body, err := io.ReadAll(resp.Body)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, string(body), strings.Repeat("0123456789", 10))
+	})
+
+	t.Run("should fail streams larger than the max size while reading", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout:         time.Second,
+			MaxResponseSize: 50,
+		})
+
+		resp, err := client.Stream(ctx, "GET", server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		tt.AssertErrContains(t, err, "larger than the maximum allowed size")
+		tt.AssertEqual(t, len(body), 50)
+	})
+
+	t.Run("should return status errors when streaming", func(t *testing.T) {
+		client := New(time.Second)
+
+		resp, err := client.Stream(ctx, "GET", server.URL+"/not-found", rest.RequestData{})
+		tt.AssertErrContains(t, err, "404")
+		defer resp.Body.Close()
+
+		tt.AssertEqual(t, resp.StatusCode, 404)
+		body, err := io.ReadAll(resp.Body)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(body), 100)
+	})
+}
+
+func TestRequestBodies(t *testing.T) {
+	ctx := context.Background()
+
+	var receivedContentType string
+	var receivedForm url.Values
+	var receivedFile []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedContentType = r.Header.Get("Content-Type")
+		receivedForm = nil
+		receivedFile = nil
+
+		if strings.HasPrefix(receivedContentType, "multipart/form-data") {
+			err := r.ParseMultipartForm(1024)
+			tt.AssertNoErr(t, err)
+			receivedForm = r.MultipartForm.Value
+
+			file, _, err := r.FormFile("photo")
+			tt.AssertNoErr(t, err)
+			receivedFile, _ = io.ReadAll(file)
+			return
+		}
+
+		r.ParseForm()
+		receivedForm = r.PostForm
+	}))
+	defer server.Close()
+
+	client := NewWithConfig(Config{
+		Timeout: time.Second,
+		Retry:   RetryPolicy{MaxAttempts: 2},
+	})
+
+	t.Run("should send forms as application/x-www-form-urlencoded", func(t *testing.T) {
+		_, err := client.Post(ctx, server.URL, rest.RequestData{
+			Body: rest.Form{"name": {"fake name"}},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, receivedContentType, "application/x-www-form-urlencoded")
+		tt.AssertEqual(t, receivedForm, url.Values{"name": {"fake name"}})
+	})
+
+	t.Run("should send fields and files as multipart/form-data", func(t *testing.T) {
+		_, err := client.Post(ctx, server.URL, rest.RequestData{
+			Body: rest.Multipart{
+				Fields: url.Values{"name": {"fakeName"}},
+				Files: []rest.File{{
+					FieldName: "photo",
+					FileName:  "photo.png",
+					Content:   []byte("fakePNG"),
+				}},
+			},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertTrue(t, strings.HasPrefix(receivedContentType, "multipart/form-data; boundary="))
+		tt.AssertEqual(t, receivedForm, url.Values{"name": {"fakeName"}})
+		tt.AssertEqual(t, string(receivedFile), "fakePNG")
+	})
+
+	t.Run("should send structs as JSON", func(t *testing.T) {
+		_, err := client.Put(ctx, server.URL, rest.RequestData{
+			Body: map[string]string{"name": "fakeName"},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, receivedContentType, "application/json")
+	})
+
+	t.Run("should not override an explicit Content-Type", func(t *testing.T) {
+		_, err := client.Post(ctx, server.URL, rest.RequestData{
+			Headers: map[string]string{"content-type": "application/vnd.fake+json"},
+			Body:    map[string]string{"name": "fakeName"},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, receivedContentType, "application/vnd.fake+json")
+	})
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Propagation describes which values from the context should be
+// forwarded as headers on outbound requests, so we can correlate
+// our logs with the logs of the services we call.
+//
+// The zero value forwards nothing.
+type Propagation struct {
+	// RequestIDHeader is the name of the header used for forwarding
+	// the request ID, e.g. "request-id". If empty it is not forwarded.
+	RequestIDHeader string
+
+	// TraceParent enables forwarding the W3C `traceparent` header
+	// when one was received by our API, the trace ID is kept but each
+	// outbound request gets a new parent ID as required by the spec.
+	TraceParent bool
+}
+
+// propagationFor returns the Propagation rule for the host of the input URL
+func (c Client) propagationFor(rawURL string) Propagation {
+	if len(c.propagationByHost) == 0 {
+		return c.propagation
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.propagation
+	}
+
+	// Rules can be declared either with or without the port:
+	if p, ok := c.propagationByHost[u.Host]; ok {
+		return p
+	}
+	if p, ok := c.propagationByHost[u.Hostname()]; ok {
+		return p
+	}
+
+	return c.propagation
+}
+
+// propagationHeaders builds the headers that should be forwarded
+// to the host of the input URL.
+func (c Client) propagationHeaders(ctx context.Context, rawURL string) map[string]string {
+	p := c.propagationFor(rawURL)
+
+	headers := map[string]string{}
+	if p.RequestIDHeader != "" {
+		requestID := domain.GetRequestIDFromContext(ctx)
+		if requestID != "" {
+			headers[p.RequestIDHeader] = requestID
+		}
+	}
+
+	if p.TraceParent {
+		traceParent := childTraceParent(domain.GetTraceParentFromContext(ctx))
+		if traceParent != "" {
+			headers["traceparent"] = traceParent
+		}
+	}
+
+	return headers
+}
+
+// The format is <version>-<trace-id>-<parent-id>-<trace-flags>, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+var traceParentRegex = regexp.MustCompile(`^00-([0-9a-f]{32})-[0-9a-f]{16}-([0-9a-f]{2})$`)
+
+// childTraceParent returns a traceparent for an outbound request made
+// on behalf of the input one, i.e. with the same trace ID and flags but
+// with a new parent ID, so the upstream spans are not attached directly
+// to the span of our caller. Invalid traceparents are not forwarded.
+func childTraceParent(traceParent string) string {
+	match := traceParentRegex.FindStringSubmatch(traceParent)
+	if match == nil || match[1] == "00000000000000000000000000000000" {
+		return ""
+	}
+
+	parentID := make([]byte, 8)
+	for {
+		_, err := rand.Read(parentID)
+		if err != nil {
+			return ""
+		}
+
+		// The all zeros parent ID is invalid:
+		if hex.EncodeToString(parentID) != "0000000000000000" {
+			break
+		}
+	}
+
+	return "00-" + match[1] + "-" + hex.EncodeToString(parentID) + "-" + match[2]
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestPropagation(t *testing.T) {
+	var receivedHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeader = r.Header
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	tt.AssertNoErr(t, err)
+
+	ctx := context.WithValue(context.Background(), domain.RequestIDKey, "fakeRequestID")
+	ctx = context.WithValue(ctx, domain.TraceParentKey, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	tests := []struct {
+		desc                string
+		config              Config
+		headers             map[string]string
+		expectedRequestID   string
+		expectedTraceParent string
+	}{
+		{
+			desc:   "should not forward anything by default",
+			config: Config{},
+		},
+		{
+			desc: "should forward the request ID and the traceparent",
+			config: Config{
+				Propagation: Propagation{
+					RequestIDHeader: "request-id",
+					TraceParent:     true,
+				},
+			},
+			expectedRequestID:   "fakeRequestID",
+			expectedTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-<new-parent-id>-01",
+		},
+		{
+			desc: "should use the rule for the destination host when available",
+			config: Config{
+				Propagation: Propagation{
+					RequestIDHeader: "request-id",
+					TraceParent:     true,
+				},
+				PropagationByHost: map[string]Propagation{
+					serverURL.Hostname(): {
+						RequestIDHeader: "request-id",
+					},
+				},
+			},
+			expectedRequestID: "fakeRequestID",
+		},
+		{
+			desc: "should not overwrite explicit headers",
+			config: Config{
+				Propagation: Propagation{
+					RequestIDHeader: "request-id",
+				},
+			},
+			headers: map[string]string{
+				"request-id": "explicitRequestID",
+			},
+			expectedRequestID: "explicitRequestID",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			test.config.Timeout = time.Second
+			client := NewWithConfig(test.config)
+
+			_, err := client.Get(ctx, server.URL, rest.RequestData{
+				Headers: test.headers,
+			})
+			tt.AssertNoErr(t, err)
+
+			tt.AssertEqual(t, receivedHeader.Get("request-id"), test.expectedRequestID)
+
+			traceParent := receivedHeader.Get("traceparent")
+			if test.expectedTraceParent == "" {
+				tt.AssertEqual(t, traceParent, "")
+				return
+			}
+
+			// The parent ID is random, so only its format is checked:
+			tt.AssertTrue(t, traceParentRegex.MatchString(traceParent))
+			parts := strings.Split(traceParent, "-")
+			tt.AssertTrue(t, parts[2] != "00f067aa0ba902b7")
+			parts[2] = "<new-parent-id>"
+			tt.AssertEqual(t, strings.Join(parts, "-"), test.expectedTraceParent)
+		})
+	}
+}
+
+func TestChildTraceParent(t *testing.T) {
+	t.Run("should keep the trace ID and flags with a new parent ID", func(t *testing.T) {
+		child1 := childTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		child2 := childTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		tt.AssertTrue(t, strings.HasPrefix(child1, "00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+		tt.AssertTrue(t, strings.HasSuffix(child1, "-01"))
+		tt.AssertTrue(t, !strings.Contains(child1, "00f067aa0ba902b7"))
+		tt.AssertTrue(t, child1 != child2)
+	})
+
+	t.Run("should not forward invalid traceparents", func(t *testing.T) {
+		tt.AssertEqual(t, childTraceParent(""), "")
+		tt.AssertEqual(t, childTraceParent("not-a-traceparent"), "")
+		tt.AssertEqual(t, childTraceParent("00-00000000000000000000000000000000-00f067aa0ba902b7-01"), "")
+		tt.AssertEqual(t, childTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"), "")
+	})
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// RetryPolicy describes when and how a failed request should be retried.
+//
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one,
+	// values lower than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the maximum wait before the second attempt,
+	// each following attempt doubles it up to MaxBackoff.
+	//
+	// The actual wait is a random value between zero and the
+	// current backoff (i.e. "full jitter"), so several clients
+	// failing at the same time won't retry at the same time.
+	//
+	// Defaults to 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the exponential backoff and also the wait
+	// requested by the server on the `Retry-After` header: if the
+	// server asks us to wait longer than this we give up instead.
+	//
+	// Defaults to 10s.
+	MaxBackoff time.Duration
+
+	// RetryableStatusCodes lists the status codes that should be retried,
+	// network errors are always retried.
+	//
+	// Defaults to 429, 502, 503 and 504.
+	RetryableStatusCodes []int
+
+	// By default only idempotent methods (GET, HEAD, OPTIONS, PUT and DELETE)
+	// are retried, set this to true to also retry POST and PATCH requests.
+	RetryNonIdempotent bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 10 * time.Second
+	}
+	if p.RetryableStatusCodes == nil {
+		p.RetryableStatusCodes = []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	return p
+}
+
+// errNoAttemptsLeft is returned when a request is not sent because
+// the other requests of the same call used all the attempts
+var errNoAttemptsLeft = errors.New("no attempts left for sending the request")
+
+// attemptBudget limits the number of attempts shared by all
+// the requests of a single call, e.g. the hedged requests
+type attemptBudget struct {
+	mutex     sync.Mutex
+	remaining int
+}
+
+func newAttemptBudget(maxAttempts int) *attemptBudget {
+	return &attemptBudget{
+		remaining: maxAttempts,
+	}
+}
+
+// take reserves one attempt, returning false if none are left
+func (b *attemptBudget) take() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// left returns how many attempts are still available
+func (b *attemptBudget) left() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.remaining
+}
+
+// maxAttemptsFor returns how many attempts are allowed for the input method
+func (p RetryPolicy) maxAttemptsFor(method string) int {
+	if p.MaxAttempts < 2 {
+		return 1
+	}
+	if !p.RetryNonIdempotent && !isIdempotent(method) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// nextWait decides if the request should be retried after the input
+// attempt and, if so, for how long we should wait before trying again.
+func (p RetryPolicy) nextWait(ctx context.Context, attempt int, resp rest.Response, err error) (wait time.Duration, retry bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	// A zero status code means we didn't get any response, i.e. a network error:
+	if resp.StatusCode == 0 {
+		if err == nil {
+			return 0, false
+		}
+
+		// Requests rejected by a rate limiter on the transport already
+		// waited as long as they could, so retrying would only wait again:
+		if domain.AsDomainErr(err).Code == "RateLimitedErr" {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+
+	if !containsInt(p.RetryableStatusCodes, resp.StatusCode) {
+		return 0, false
+	}
+
+	retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return p.backoff(attempt), true
+	}
+
+	if retryAfter > p.MaxBackoff {
+		return 0, false
+	}
+	return retryAfter, true
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// parseRetryAfter parses both formats allowed for the `Retry-After` header,
+// i.e. a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+func containsInt(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sleep waits for the input duration or until the context is canceled,
+// it returns false if the context was canceled.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,220 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		desc             string
+		method           string
+		policy           RetryPolicy
+		statusCodes      []int
+		retryAfter       string
+		expectedAttempts int
+		expectedStatus   int
+		expectErr        bool
+	}{
+		{
+			desc:             "should not retry when retries are disabled",
+			method:           "GET",
+			statusCodes:      []int{503, 200},
+			expectedAttempts: 1,
+			expectedStatus:   503,
+			expectErr:        true,
+		},
+		{
+			desc:             "should retry retryable status codes until it succeeds",
+			method:           "GET",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			statusCodes:      []int{503, 502, 200},
+			expectedAttempts: 3,
+			expectedStatus:   200,
+		},
+		{
+			desc:             "should stop after the max number of attempts",
+			method:           "GET",
+			policy:           RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			statusCodes:      []int{503, 503, 200},
+			expectedAttempts: 2,
+			expectedStatus:   503,
+			expectErr:        true,
+		},
+		{
+			desc:             "should not retry status codes that are not retryable",
+			method:           "GET",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			statusCodes:      []int{400, 200},
+			expectedAttempts: 1,
+			expectedStatus:   400,
+			expectErr:        true,
+		},
+		{
+			desc:             "should not retry non idempotent methods by default",
+			method:           "POST",
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			statusCodes:      []int{503, 200},
+			expectedAttempts: 1,
+			expectedStatus:   503,
+			expectErr:        true,
+		},
+		{
+			desc:   "should retry non idempotent methods if configured to do so",
+			method: "POST",
+			policy: RetryPolicy{
+				MaxAttempts:        3,
+				InitialBackoff:     time.Millisecond,
+				RetryNonIdempotent: true,
+			},
+			statusCodes:      []int{503, 200},
+			expectedAttempts: 2,
+			expectedStatus:   200,
+		},
+		{
+			desc:             "should give up if Retry-After is longer than the max backoff",
+			method:           "GET",
+			policy:           RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second},
+			statusCodes:      []int{429, 200},
+			retryAfter:       "120",
+			expectedAttempts: 1,
+			expectedStatus:   429,
+			expectErr:        true,
+		},
+		{
+			desc:             "should honor Retry-After when it is within the max backoff",
+			method:           "GET",
+			policy:           RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Second},
+			statusCodes:      []int{429, 200},
+			retryAfter:       "0",
+			expectedAttempts: 2,
+			expectedStatus:   200,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			statusCodes := test.statusCodes
+			var attempts int32
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(tt.NextResponse(t, &statusCodes))
+			}))
+			defer server.Close()
+
+			var retryLogs int
+			client := NewWithConfig(Config{
+				Timeout: time.Second,
+				Retry:   test.policy,
+				Logger: log.Mock{
+					WarnFn: func(ctx context.Context, title string, valueMaps ...log.Body) {
+						if title == "retrying-rest-request" {
+							retryLogs++
+						}
+					},
+				},
+			})
+
+			resp, err := client.makeRequest(ctx, test.method, server.URL, rest.RequestData{
+				Body: "fakeBody",
+			})
+			if test.expectErr {
+				tt.AssertErrContains(t, err, "unexpected status code")
+			} else {
+				tt.AssertNoErr(t, err)
+			}
+
+			tt.AssertEqual(t, resp.StatusCode, test.expectedStatus)
+			tt.AssertEqual(t, int(atomic.LoadInt32(&attempts)), test.expectedAttempts)
+			tt.AssertEqual(t, retryLogs, test.expectedAttempts-1)
+			for _, body := range bodies {
+				tt.AssertEqual(t, body, "fakeBody")
+			}
+		})
+	}
+
+	t.Run("should stop retrying when the context is canceled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(503)
+		}))
+		defer server.Close()
+
+		client := NewWithConfig(Config{
+			Timeout: time.Second,
+			Retry: RetryPolicy{
+				MaxAttempts:    10,
+				InitialBackoff: time.Hour,
+				MaxBackoff:     time.Hour,
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		startTime := time.Now()
+		resp, err := client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertErrContains(t, err, "503")
+		tt.AssertEqual(t, resp.StatusCode, 503)
+		tt.AssertTrue(t, time.Since(startTime) < time.Second)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 5, 14, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc         string
+		value        string
+		expectedWait time.Duration
+		expectedOK   bool
+	}{
+		{
+			desc:       "should ignore empty values",
+			value:      "",
+			expectedOK: false,
+		},
+		{
+			desc:         "should parse seconds",
+			value:        "5",
+			expectedWait: 5 * time.Second,
+			expectedOK:   true,
+		},
+		{
+			desc:         "should parse http dates",
+			value:        "Fri, 14 May 2021 10:00:30 GMT",
+			expectedWait: 30 * time.Second,
+			expectedOK:   true,
+		},
+		{
+			desc:       "should ignore invalid values",
+			value:      "not a date",
+			expectedOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			wait, ok := parseRetryAfter(test.value, now)
+			tt.AssertEqual(t, ok, test.expectedOK)
+			tt.AssertEqual(t, wait, test.expectedWait)
+		})
+	}
+}
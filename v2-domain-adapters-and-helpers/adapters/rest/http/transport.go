@@ -0,0 +1,196 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransportConfig describes how connections to the upstream hosts are made,
+// all fields are optional and the zero value uses the same defaults as
+// the `http.DefaultTransport`.
+type TransportConfig struct {
+	// ProxyURL is the proxy used for all requests, e.g. "http://proxy.corp:3128".
+	// If empty the HTTP_PROXY, HTTPS_PROXY and NO_PROXY env variables are used.
+	ProxyURL string
+
+	// NoProxy lists the hosts that should be reached directly when a ProxyURL
+	// is set, using the same format as the NO_PROXY env variable, e.g.:
+	// "localhost,127.0.0.1,10.0.0.0/8,.internal.corp"
+	NoProxy string
+
+	// CABundleFile is a PEM file with root certificates that are
+	// trusted in addition to the ones of the system.
+	CABundleFile string
+
+	// ClientCertFile and ClientKeyFile are PEM files with the client
+	// certificate used for mutual TLS, both must be set to enable it.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// MaxIdleConnsPerHost limits the connections kept open for reuse
+	// with each host. Defaults to 2, as in the http package.
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long idle connections are kept open. Defaults to 90s.
+	IdleConnTimeout time.Duration
+
+	// DialTimeout limits the time spent opening a connection. Defaults to 30s.
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout limits the time spent on the TLS handshake. Defaults to 10s.
+	TLSHandshakeTimeout time.Duration
+}
+
+// NewTransport builds a transport for the Client according to the input
+// config, it returns an error if any of the certificate files is invalid.
+func NewTransport(config TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = proxyFunc(proxyURL, parseNoProxy(config.NoProxy))
+	}
+
+	if config.DialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   config.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if config.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = config.IdleConnTimeout
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+
+	if config.CABundleFile == "" && config.ClientCertFile == "" && config.ClientKeyFile == "" {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if config.CABundleFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(config.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found on CA bundle '%s'", config.CABundleFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// noProxyRule is a single entry of a NO_PROXY list
+type noProxyRule struct {
+	all    bool
+	domain string
+	port   string
+	ipNet  *net.IPNet
+}
+
+func parseNoProxy(noProxy string) []noProxyRule {
+	var rules []noProxyRule
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if entry == "*" {
+			rules = append(rules, noProxyRule{all: true})
+			continue
+		}
+
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			rules = append(rules, noProxyRule{ipNet: ipNet})
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(entry)
+		if err != nil {
+			host, port = entry, ""
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			rules = append(rules, noProxyRule{ipNet: &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}, port: port})
+			continue
+		}
+
+		// Both "example.com" and ".example.com" match the domain and its subdomains:
+		rules = append(rules, noProxyRule{
+			domain: strings.TrimPrefix(strings.TrimPrefix(host, "*"), "."),
+			port:   port,
+		})
+	}
+	return rules
+}
+
+func (r noProxyRule) matches(host string, port string) bool {
+	if r.all {
+		return true
+	}
+	if r.port != "" && r.port != port {
+		return false
+	}
+
+	if r.ipNet != nil {
+		ip := net.ParseIP(host)
+		return ip != nil && r.ipNet.Contains(ip)
+	}
+
+	return host == r.domain || strings.HasSuffix(host, "."+r.domain)
+}
+
+// proxyFunc returns a function that sends all requests through
+// the input proxy except the ones matching the noProxy rules.
+func proxyFunc(proxyURL *url.URL, noProxy []noProxyRule) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		host := strings.ToLower(req.URL.Hostname())
+		port := req.URL.Port()
+		if port == "" {
+			port = "80"
+			if req.URL.Scheme == "https" {
+				port = "443"
+			}
+		}
+
+		for _, rule := range noProxy {
+			if rule.matches(host, port) {
+				return nil, nil
+			}
+		}
+		return proxyURL, nil
+	}
+}
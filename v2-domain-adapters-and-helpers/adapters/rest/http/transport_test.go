@@ -0,0 +1,213 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestNoProxy(t *testing.T) {
+	tests := []struct {
+		desc          string
+		noProxy       string
+		url           string
+		expectedProxy bool
+	}{
+		{
+			desc:          "should use the proxy when there are no rules",
+			url:           "https://api.foursquare.com/v2",
+			expectedProxy: true,
+		},
+		{
+			desc:          "should skip the proxy for matching domains and subdomains",
+			noProxy:       "localhost, .internal.corp",
+			url:           "http://api.internal.corp/foo",
+			expectedProxy: false,
+		},
+		{
+			desc:          "should not match domains that only share a suffix",
+			noProxy:       "internal.corp",
+			url:           "http://notinternal.corp/foo",
+			expectedProxy: true,
+		},
+		{
+			desc:          "should match IP ranges",
+			noProxy:       "10.0.0.0/8",
+			url:           "http://10.1.2.3:8080/foo",
+			expectedProxy: false,
+		},
+		{
+			desc:          "should match ports using the default port of the scheme",
+			noProxy:       "api.partner.com:443",
+			url:           "https://api.partner.com/foo",
+			expectedProxy: false,
+		},
+		{
+			desc:          "should use the proxy for other ports",
+			noProxy:       "api.partner.com:443",
+			url:           "http://api.partner.com/foo",
+			expectedProxy: true,
+		},
+		{
+			desc:          "should skip the proxy for all hosts with a wildcard",
+			noProxy:       "*",
+			url:           "https://api.foursquare.com/v2",
+			expectedProxy: false,
+		},
+	}
+
+	proxyURL, _ := url.Parse("http://proxy.corp:3128")
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.url, nil)
+			tt.AssertNoErr(t, err)
+
+			proxy, err := proxyFunc(proxyURL, parseNoProxy(test.noProxy))(req)
+			tt.AssertNoErr(t, err)
+			tt.AssertEqual(t, proxy != nil, test.expectedProxy)
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should send requests through the proxy", func(t *testing.T) {
+		var proxiedURL string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedURL = r.URL.String()
+		}))
+		defer proxy.Close()
+
+		transport, err := NewTransport(TransportConfig{
+			ProxyURL: proxy.URL,
+		})
+		tt.AssertNoErr(t, err)
+
+		client := NewWithConfig(Config{
+			Timeout:   time.Second,
+			Transport: transport,
+		})
+		_, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, proxiedURL, "http://fake.host/foo")
+	})
+
+	t.Run("should trust the certificates on the CA bundle", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		// Without the bundle the certificate of the test server is not trusted:
+		_, err := New(time.Second).Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertErrContains(t, err, "certificate")
+
+		caFile := writePEM(t, "CERTIFICATE", server.Certificate().Raw)
+		transport, err := NewTransport(TransportConfig{
+			CABundleFile: caFile,
+		})
+		tt.AssertNoErr(t, err)
+
+		client := NewWithConfig(Config{
+			Timeout:   time.Second,
+			Transport: transport,
+		})
+		_, err = client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+	})
+
+	t.Run("should send the client certificate for mutual TLS", func(t *testing.T) {
+		certFile, keyFile, cert := newClientCert(t)
+
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(cert)
+
+		var receivedCN string
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}))
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		}
+		server.StartTLS()
+		defer server.Close()
+
+		transport, err := NewTransport(TransportConfig{
+			CABundleFile:   writePEM(t, "CERTIFICATE", server.Certificate().Raw),
+			ClientCertFile: certFile,
+			ClientKeyFile:  keyFile,
+		})
+		tt.AssertNoErr(t, err)
+
+		client := NewWithConfig(Config{
+			Timeout:   time.Second,
+			Transport: transport,
+		})
+		_, err = client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, receivedCN, "fake-client")
+	})
+
+	t.Run("should report invalid certificate files", func(t *testing.T) {
+		_, err := NewTransport(TransportConfig{
+			CABundleFile: writePEM(t, "FAKE", []byte("fake")),
+		})
+		tt.AssertErrContains(t, err, "no valid certificates")
+
+		_, err = NewTransport(TransportConfig{
+			ClientCertFile: "/not/a/file.pem",
+			ClientKeyFile:  "/not/a/file.key",
+		})
+		tt.AssertErrContains(t, err, "unable to load client certificate")
+	})
+}
+
+func writePEM(t *testing.T, blockType string, content []byte) string {
+	path := filepath.Join(t.TempDir(), "file.pem")
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: content}), 0600)
+	tt.AssertNoErr(t, err)
+	return path
+}
+
+// newClientCert generates a self-signed client certificate
+func newClientCert(t *testing.T) (certFile string, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tt.AssertNoErr(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rawCert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	tt.AssertNoErr(t, err)
+
+	cert, err = x509.ParseCertificate(rawCert)
+	tt.AssertNoErr(t, err)
+
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	tt.AssertNoErr(t, err)
+
+	return writePEM(t, "CERTIFICATE", rawCert), writePEM(t, "EC PRIVATE KEY", rawKey), cert
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+// buildURL prepends the base URL to relative URLs and
+// appends the encoded query params to the result.
+func (c Client) buildURL(rawURL string, query url.Values) (string, error) {
+	if c.baseURL != "" && !strings.Contains(rawURL, "://") {
+		rawURL = strings.TrimRight(c.baseURL, "/") + "/" + strings.TrimLeft(rawURL, "/")
+	}
+
+	fullURL, err := rest.AppendQuery(rawURL, query)
+	if err != nil {
+		return "", c.redactErr(err)
+	}
+	return fullURL, nil
+}
+
+// redactURL removes all sensitive query params from the input URL
+// so it can be safely logged or added to error messages.
+func (c Client) redactURL(rawURL string) string {
+	return rest.RedactURL(rawURL, c.sensitiveQueryParams...)
+}
+
+// redactErr removes sensitive query params from the URL contained
+// on errors returned by the net/url and net/http packages.
+func (c Client) redactErr(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+
+	return &url.Error{
+		Op:  urlErr.Op,
+		URL: c.redactURL(urlErr.URL),
+		Err: urlErr.Err,
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestURLBuilding(t *testing.T) {
+	ctx := context.Background()
+
+	var receivedURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedURL = r.URL.String()
+		w.WriteHeader(400)
+	}))
+	defer server.Close()
+
+	t.Run("should prepend the base URL and encode the query params", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout: time.Second,
+			BaseURL: server.URL + "/v2/",
+		})
+
+		_, err := client.Get(ctx, "/venues/search?v=20210514", rest.RequestData{
+			Query: url.Values{
+				"ll":    {"40.7,-74"},
+				"query": {"coffee & tea"},
+			},
+		})
+		tt.AssertErrContains(t, err, "400")
+		tt.AssertEqual(t, receivedURL, "/v2/venues/search?ll=40.7%2C-74&query=coffee+%26+tea&v=20210514")
+	})
+
+	t.Run("should not use the base URL for absolute URLs", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout: time.Second,
+			BaseURL: "http://other.host/v2",
+		})
+
+		_, err := client.Get(ctx, server.URL+"/foo", rest.RequestData{})
+		tt.AssertErrContains(t, err, "400")
+		tt.AssertEqual(t, receivedURL, "/foo")
+	})
+
+	t.Run("should redact sensitive params from status errors", func(t *testing.T) {
+		client := NewWithConfig(Config{
+			Timeout:              time.Second,
+			SensitiveQueryParams: []string{"custom_token"},
+		})
+
+		_, err := client.Get(ctx, server.URL, rest.RequestData{
+			Query: url.Values{
+				"client_secret": {"fakeSecret"},
+				"custom_token":  {"fakeToken"},
+			},
+		})
+		tt.AssertErrContains(t, err, "client_secret=REDACTED", "custom_token=REDACTED")
+		tt.AssertFalse(t, strings.Contains(err.Error(), "fakeSecret"))
+		tt.AssertFalse(t, strings.Contains(err.Error(), "fakeToken"))
+	})
+
+	t.Run("should redact sensitive params from network errors", func(t *testing.T) {
+		closedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		closedServer.Close()
+
+		client := NewWithConfig(Config{
+			Timeout: time.Second,
+		})
+
+		_, err := client.Get(ctx, closedServer.URL, rest.RequestData{
+			Query: url.Values{
+				"client_secret": {"fakeSecret"},
+			},
+		})
+		tt.AssertErrContains(t, err, "client_secret=REDACTED")
+		tt.AssertFalse(t, strings.Contains(err.Error(), "fakeSecret"))
+	})
+}
@@ -0,0 +1,266 @@
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+// Client is a rest.Provider decorator that caches the responses of GET
+// requests on any cache.Provider following the HTTP caching rules:
+//
+//   - Responses are only stored if the upstream allows it, i.e. no `no-store`
+//     on the `Cache-Control` header, and if they have either a freshness
+//     lifetime (`max-age` or `Expires`) or a validator (`ETag` or `Last-Modified`);
+//   - Fresh responses are served directly from the cache;
+//   - Stale responses are revalidated with `If-None-Match` and `If-Modified-Since`,
+//     and if the upstream answers with a 304 the stored body is reused.
+//
+// All other methods are forwarded to the decorated provider untouched.
+type Client struct {
+	next   rest.Provider
+	cache  cache.Provider
+	config Config
+	now    func() time.Time
+}
+
+// Config describes the optional configurations of the cache
+type Config struct {
+	// DefaultTTL is the freshness lifetime used for responses that
+	// have no explicit freshness information. Defaults to zero,
+	// meaning these responses are always revalidated.
+	DefaultTTL time.Duration
+
+	// KeyPrefix is prepended to all cache keys. Defaults to "httpcache:".
+	KeyPrefix string
+
+	// Logger is used for reporting cache errors and is optional
+	Logger log.Provider
+}
+
+// entry is the record saved on the cache for each response
+type entry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+
+	ExpiresAt    time.Time `json:"expires_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// New instantiates a new http cache decorating the input rest.Provider
+func New(next rest.Provider, cache cache.Provider, config Config) Client {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "httpcache:"
+	}
+
+	return Client{
+		next:   next,
+		cache:  cache,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Get implements the rest.Provider interface
+func (c Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	fullURL, err := rest.AppendQuery(url, data.Query)
+	if err != nil {
+		return c.next.Get(ctx, url, data)
+	}
+	key := c.cacheKey(fullURL, data.Headers)
+
+	var cached entry
+	err = c.cache.Get(ctx, key, &cached)
+	found := err == nil
+	if found && c.now().Before(cached.ExpiresAt) {
+		return cached.response(), nil
+	}
+
+	if found {
+		data.Headers = withValidators(data.Headers, cached)
+	}
+
+	resp, err := c.next.Get(ctx, url, data)
+	if found && resp.StatusCode == http.StatusNotModified {
+		// The 304 response might carry updated freshness information:
+		cached.ExpiresAt = c.expiresAt(resp.Header)
+		c.save(ctx, key, fullURL, cached)
+		return cached.response(), nil
+	}
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	if !isStorable(resp.Header) {
+		return resp, nil
+	}
+
+	e := entry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header,
+		Body:         resp.Body,
+		ExpiresAt:    c.expiresAt(resp.Header),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	// Responses that are already stale and can't be revalidated are useless:
+	if !e.ExpiresAt.After(c.now()) && e.ETag == "" && e.LastModified == "" {
+		return resp, nil
+	}
+
+	c.save(ctx, key, fullURL, e)
+	return resp, nil
+}
+
+// Post implements the rest.Provider interface
+func (c Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.next.Post(ctx, url, data)
+}
+
+// Put implements the rest.Provider interface
+func (c Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.next.Put(ctx, url, data)
+}
+
+// Patch implements the rest.Provider interface
+func (c Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.next.Patch(ctx, url, data)
+}
+
+// Delete implements the rest.Provider interface
+func (c Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	return c.next.Delete(ctx, url, data)
+}
+
+// Stream implements the rest.Streamer interface,
+// streamed responses are never cached.
+func (c Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	return rest.Stream(ctx, c.next, method, url, data)
+}
+
+func (e entry) response() rest.Response {
+	return rest.Response{
+		Body:       e.Body,
+		Header:     e.Header,
+		StatusCode: e.StatusCode,
+	}
+}
+
+func (c Client) save(ctx context.Context, key string, fullURL string, e entry) {
+	err := c.cache.Set(ctx, key, e)
+	if err != nil && c.config.Logger != nil {
+		c.config.Logger.Warn(ctx, "unable-to-save-response-on-http-cache", log.Body{
+			"url":   rest.RedactURL(fullURL),
+			"error": err.Error(),
+		})
+	}
+}
+
+// cacheKey hashes the URL and the request headers, so different credentials
+// never share the same entry and no secrets are ever used as cache keys.
+func (c Client) cacheKey(fullURL string, headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	hash.Write([]byte(fullURL))
+	for _, name := range names {
+		hash.Write([]byte("\n" + strings.ToLower(name) + ":" + headers[name]))
+	}
+
+	return c.config.KeyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// expiresAt computes until when a response can be served
+// without revalidation based on its headers.
+func (c Client) expiresAt(header http.Header) time.Time {
+	now := c.now()
+
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, ok := directives["no-cache"]; ok {
+		return now
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds < 0 {
+			return now
+		}
+
+		// The Age header tells us how long the response was stored on other caches:
+		age, _ := strconv.Atoi(header.Get("Age"))
+		return now.Add(time.Duration(seconds-age) * time.Second)
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid dates such as "0" mean the response is already expired
+			return now
+		}
+
+		// Using the date of the upstream avoids problems with clock skew:
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			return expiresAt
+		}
+		return now.Add(expiresAt.Sub(date))
+	}
+
+	return now.Add(c.config.DefaultTTL)
+}
+
+func isStorable(header http.Header) bool {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	return true
+}
+
+func withValidators(headers map[string]string, cached entry) map[string]string {
+	result := map[string]string{}
+	for k, v := range headers {
+		result[k] = v
+	}
+
+	if cached.ETag != "" {
+		result["If-None-Match"] = cached.ETag
+	}
+	if cached.LastModified != "" {
+		result["If-Modified-Since"] = cached.LastModified
+	}
+	return result
+}
+
+// parseCacheControl parses a header such as `public, max-age=60`
+// into a map of directives to their values, e.g.:
+// `{"public": "", "max-age": "60"}`
+func parseCacheControl(value string) map[string]string {
+	directives := map[string]string{}
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(directive, "=")
+		directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+	}
+	return directives
+}
@@ -0,0 +1,127 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache/memorycache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+
+	type call struct {
+		headers map[string]string
+	}
+
+	tests := []struct {
+		desc string
+
+		// Responses returned by the upstream in order:
+		responses []rest.Response
+
+		// How long to wait between the first and the second request:
+		elapsed time.Duration
+
+		expectedCalls       int
+		expectedBody        string
+		expectedIfNoneMatch string
+	}{
+		{
+			desc: "should serve fresh responses from the cache",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{"Cache-Control": {"max-age=60"}}},
+			},
+			elapsed:       30 * time.Second,
+			expectedCalls: 1,
+			expectedBody:  "first",
+		},
+		{
+			desc: "should fetch the response again after it expires",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{"Cache-Control": {"max-age=60"}}},
+				{StatusCode: 200, Body: []byte("second")},
+			},
+			elapsed:       61 * time.Second,
+			expectedCalls: 2,
+			expectedBody:  "second",
+		},
+		{
+			desc: "should never store responses with no-store",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{"Cache-Control": {"no-store, max-age=60"}}},
+				{StatusCode: 200, Body: []byte("second")},
+			},
+			expectedCalls: 2,
+			expectedBody:  "second",
+		},
+		{
+			desc: "should honor the Expires header relative to the upstream date",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{
+					"Date":    {"Fri, 14 May 2021 08:00:00 GMT"},
+					"Expires": {"Fri, 14 May 2021 08:01:00 GMT"},
+				}},
+			},
+			elapsed:       30 * time.Second,
+			expectedCalls: 1,
+			expectedBody:  "first",
+		},
+		{
+			desc: "should reuse the stored body when revalidation returns 304",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}},
+				{StatusCode: 304},
+			},
+			expectedCalls:       2,
+			expectedBody:        "first",
+			expectedIfNoneMatch: `"v1"`,
+		},
+		{
+			desc: "should replace the stored response when revalidation returns 200",
+			responses: []rest.Response{
+				{StatusCode: 200, Body: []byte("first"), Header: http.Header{"Cache-Control": {"max-age=0"}, "Etag": {`"v1"`}}},
+				{StatusCode: 200, Body: []byte("second"), Header: http.Header{"Etag": {`"v2"`}}},
+			},
+			expectedCalls:       2,
+			expectedBody:        "second",
+			expectedIfNoneMatch: `"v1"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			responses := test.responses
+			var calls []call
+			client := New(rest.Mock{
+				GetFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+					calls = append(calls, call{headers: data.Headers})
+					return tt.NextResponse(t, &responses), nil
+				},
+			}, memorycache.New(time.Hour, time.Hour), Config{})
+
+			now := time.Date(2021, 5, 14, 10, 0, 0, 0, time.UTC)
+			client.now = func() time.Time { return now }
+
+			resp, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+			tt.AssertEqual(t, string(resp.Body), "first")
+
+			now = now.Add(test.elapsed)
+
+			resp, err = client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+			tt.AssertEqual(t, resp.StatusCode, 200)
+			tt.AssertEqual(t, string(resp.Body), test.expectedBody)
+
+			tt.AssertEqual(t, len(calls), test.expectedCalls)
+			if test.expectedCalls > 1 {
+				tt.AssertEqual(t, calls[1].headers["If-None-Match"], test.expectedIfNoneMatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// GetJSON makes a GET request and parses the response body as JSON into T
+//
+// Usage example:
+//
+//	user, err := rest.GetJSON[User](ctx, restProvider, url, rest.RequestData{})
+func GetJSON[T any](ctx context.Context, provider Provider, url string, data RequestData) (T, error) {
+	resp, err := provider.Get(ctx, url, data)
+	return decodeJSON[T]("GET", url, resp, err)
+}
+
+// PostJSON makes a POST request and parses the response body as JSON into T
+func PostJSON[T any](ctx context.Context, provider Provider, url string, data RequestData) (T, error) {
+	resp, err := provider.Post(ctx, url, data)
+	return decodeJSON[T]("POST", url, resp, err)
+}
+
+// PutJSON makes a PUT request and parses the response body as JSON into T
+func PutJSON[T any](ctx context.Context, provider Provider, url string, data RequestData) (T, error) {
+	resp, err := provider.Put(ctx, url, data)
+	return decodeJSON[T]("PUT", url, resp, err)
+}
+
+// PatchJSON makes a PATCH request and parses the response body as JSON into T
+func PatchJSON[T any](ctx context.Context, provider Provider, url string, data RequestData) (T, error) {
+	resp, err := provider.Patch(ctx, url, data)
+	return decodeJSON[T]("PATCH", url, resp, err)
+}
+
+// DeleteJSON makes a DELETE request and parses the response body as JSON into T
+func DeleteJSON[T any](ctx context.Context, provider Provider, url string, data RequestData) (T, error) {
+	resp, err := provider.Delete(ctx, url, data)
+	return decodeJSON[T]("DELETE", url, resp, err)
+}
+
+func decodeJSON[T any](method string, url string, resp Response, err error) (T, error) {
+	var record T
+	if err != nil {
+		return record, err
+	}
+
+	err = json.Unmarshal(resp.Body, &record)
+	if err != nil {
+		return record, domain.InternalErr("unable-to-parse-upstream-response-as-json", map[string]interface{}{
+			"method":      method,
+			"url":         RedactURL(url),
+			"status_code": resp.StatusCode,
+			"error":       err.Error(),
+			"payload":     string(resp.Body),
+		})
+	}
+
+	return record, nil
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestGetJSON(t *testing.T) {
+	ctx := context.Background()
+
+	type fakeRecord struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("should parse the response body", func(t *testing.T) {
+		record, err := GetJSON[fakeRecord](ctx, Mock{
+			GetFn: func(ctx context.Context, url string, data RequestData) (resp Response, err error) {
+				return Response{StatusCode: 200, Body: []byte(`{"name":"fakeName"}`)}, nil
+			},
+		}, "http://fake.host/foo", RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, record, fakeRecord{Name: "fakeName"})
+	})
+
+	t.Run("should report invalid JSON without leaking secrets", func(t *testing.T) {
+		_, err := GetJSON[fakeRecord](ctx, Mock{
+			GetFn: func(ctx context.Context, url string, data RequestData) (resp Response, err error) {
+				return Response{StatusCode: 200, Body: []byte(`not json`)}, nil
+			},
+		}, "http://fake.host/foo?client_secret=fakeSecret", RequestData{})
+
+		domainErr := domain.AsDomainErr(err)
+		tt.AssertEqual(t, domainErr.Code, "InternalErr")
+		tt.AssertEqual(t, domainErr.Data["url"], "http://fake.host/foo?client_secret=REDACTED")
+	})
+
+	t.Run("should return status errors untouched", func(t *testing.T) {
+		_, err := GetJSON[fakeRecord](ctx, Mock{
+			GetFn: func(ctx context.Context, url string, data RequestData) (resp Response, err error) {
+				return Response{StatusCode: 503}, NewStatusError("GET", url, 503, []byte("fakeBody"))
+			},
+		}, "http://fake.host/foo", RequestData{})
+
+		statusErr, ok := err.(StatusError)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, statusErr.StatusCode, 503)
+	})
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		desc         string
+		statusCode   int
+		expectedCode string
+	}{
+		{
+			desc:         "should convert 404 to NotFoundErr",
+			statusCode:   404,
+			expectedCode: "NotFoundErr",
+		},
+		{
+			desc:         "should convert 503 to UnavailableErr",
+			statusCode:   503,
+			expectedCode: "UnavailableErr",
+		},
+		{
+			desc:         "should convert other 4xx to InternalErr",
+			statusCode:   400,
+			expectedCode: "InternalErr",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := NewStatusError("GET", "http://fake.host/foo?client_secret=fakeSecret&v=1", test.statusCode, []byte("fakeBody"))
+
+			tt.AssertEqual(t, err.URL, "http://fake.host/foo?client_secret=REDACTED&v=1")
+			tt.AssertErrContains(t, err, "GET", "client_secret=REDACTED", "fakeBody")
+
+			domainErr := domain.AsDomainErr(err)
+			tt.AssertEqual(t, domainErr.Code, test.expectedCode)
+
+			// Only the internal errors, which are not sent to our clients, describe the upstream request:
+			if test.expectedCode == "InternalErr" {
+				tt.AssertEqual(t, domainErr.Data["status_code"], test.statusCode)
+				tt.AssertEqual(t, domainErr.Data["url"], "http://fake.host/foo?client_secret=REDACTED&v=1")
+			} else {
+				tt.AssertEqual(t, domainErr.Data, map[string]interface{}(nil))
+			}
+		})
+	}
+}
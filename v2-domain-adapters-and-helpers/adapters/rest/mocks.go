@@ -1,13 +1,21 @@
 package rest
 
-import "context"
+import (
+	"context"
+	"io"
+	"strings"
+)
 
+// Mock is a rest.Provider for tests, the request bodies are received
+// as they were sent by the caller, so `data.FormBody()` and `data.MultipartBody()`
+// can be used for asserting on forms and multipart bodies.
 type Mock struct {
 	GetFn    func(ctx context.Context, url string, data RequestData) (resp Response, err error)
 	PostFn   func(ctx context.Context, url string, data RequestData) (resp Response, err error)
 	PutFn    func(ctx context.Context, url string, data RequestData) (resp Response, err error)
 	PatchFn  func(ctx context.Context, url string, data RequestData) (resp Response, err error)
 	DeleteFn func(ctx context.Context, url string, data RequestData) (resp Response, err error)
+	StreamFn func(ctx context.Context, method string, url string, data RequestData) (resp StreamResponse, err error)
 }
 
 func (m Mock) Get(ctx context.Context, url string, data RequestData) (resp Response, err error) {
@@ -44,3 +52,10 @@ func (m Mock) Delete(ctx context.Context, url string, data RequestData) (resp Re
 	}
 	return Response{}, nil
 }
+
+func (m Mock) Stream(ctx context.Context, method string, url string, data RequestData) (resp StreamResponse, err error) {
+	if m.StreamFn != nil {
+		return m.StreamFn(ctx, method, url, data)
+	}
+	return StreamResponse{Body: io.NopCloser(strings.NewReader(""))}, nil
+}
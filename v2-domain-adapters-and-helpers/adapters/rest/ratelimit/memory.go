@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLimiter is an in-memory implementation of the Limiter interface
+// which only limits the requests sent by the current process.
+//
+// It uses the GCRA algorithm, which is equivalent to a token bucket
+// but only needs to store a single timestamp per bucket: the
+// "theoretical arrival time" (TAT) of the next request.
+type MemoryLimiter struct {
+	now func() time.Time
+
+	mutex sync.Mutex
+	tats  map[string]time.Time
+}
+
+// NewMemoryLimiter instantiates a new MemoryLimiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		now:  time.Now,
+		tats: map[string]time.Time{},
+	}
+}
+
+// Reserve implements the Limiter interface
+func (m *MemoryLimiter) Reserve(
+	ctx context.Context,
+	key string,
+	interval time.Duration,
+	burst int,
+	maxWait time.Duration,
+) (wait time.Duration, ok bool, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	tat, found := m.tats[key]
+	if !found || tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	wait = newTAT.Add(-time.Duration(burst) * interval).Sub(now)
+	if wait > maxWait {
+		return wait, false, nil
+	}
+	if wait < 0 {
+		wait = 0
+	}
+
+	m.tats[key] = newTAT
+	return wait, true, nil
+}
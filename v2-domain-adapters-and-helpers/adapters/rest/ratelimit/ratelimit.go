@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Limiter implements a token bucket shared by all requests
+// using the same key.
+//
+// Reserve should take one token from the bucket if it is available now or
+// within maxWait, returning how long the caller must wait before using it.
+// If no token is available within maxWait nothing is reserved and ok is false.
+//
+// This package contains an in-memory implementation, and the
+// `adapters/cache/redis` package contains an implementation
+// that coordinates the limits across several instances of the API.
+type Limiter interface {
+	Reserve(ctx context.Context, key string, interval time.Duration, burst int, maxWait time.Duration) (wait time.Duration, ok bool, err error)
+}
+
+// Limit describes the rate allowed for a single host
+type Limit struct {
+	// Rate is the number of requests allowed per second
+	Rate float64
+
+	// Burst is the number of requests allowed at once
+	// after a period of inactivity. Defaults to 1.
+	Burst int
+}
+
+// Config describes the configurations of the rate limiter
+type Config struct {
+	// LimitsByHost maps host names (with or without the port)
+	// to their limits, requests to other hosts are not limited.
+	LimitsByHost map[string]Limit
+
+	// MaxWait is how long a request may wait for a token before
+	// failing with a `domain.RateLimitedErr`, if zero requests
+	// fail immediately when no token is available.
+	MaxWait time.Duration
+
+	// Limiter defaults to an in-memory limiter, use the redis adapter
+	// for sharing the limits between several instances of the API.
+	Limiter Limiter
+
+	// KeyPrefix is prepended to the host for building the key
+	// of each bucket. Defaults to "ratelimit:".
+	KeyPrefix string
+}
+
+// Client is a rest.Provider decorator that keeps the requests
+// sent to each host under the configured rate.
+//
+// Note that it takes a single token for each call, so if the decorated
+// provider retries or hedges the requests use NewTransport instead.
+type Client struct {
+	limits
+	next rest.Provider
+}
+
+// New instantiates a new rate limiter decorating the input rest.Provider
+func New(next rest.Provider, config Config) Client {
+	return Client{
+		limits: newLimits(config),
+		next:   next,
+	}
+}
+
+// limits contains the logic shared by the Client and the Transport
+type limits struct {
+	config Config
+}
+
+func newLimits(config Config) limits {
+	if config.Limiter == nil {
+		config.Limiter = NewMemoryLimiter()
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "ratelimit:"
+	}
+
+	return limits{
+		config: config,
+	}
+}
+
+// Get implements the rest.Provider interface
+func (c Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.Response{}, err
+	}
+	return c.next.Get(ctx, url, data)
+}
+
+// Post implements the rest.Provider interface
+func (c Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.Response{}, err
+	}
+	return c.next.Post(ctx, url, data)
+}
+
+// Put implements the rest.Provider interface
+func (c Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.Response{}, err
+	}
+	return c.next.Put(ctx, url, data)
+}
+
+// Patch implements the rest.Provider interface
+func (c Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.Response{}, err
+	}
+	return c.next.Patch(ctx, url, data)
+}
+
+// Delete implements the rest.Provider interface
+func (c Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.Response{}, err
+	}
+	return c.next.Delete(ctx, url, data)
+}
+
+// Stream implements the rest.Streamer interface
+func (c Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	if err := c.wait(ctx, url); err != nil {
+		return rest.StreamResponse{}, err
+	}
+	return rest.Stream(ctx, c.next, method, url, data)
+}
+
+// wait blocks until the request is allowed by the limiter,
+// or returns a domain.RateLimitedErr if it would take too long.
+func (c limits) wait(ctx context.Context, rawURL string) error {
+	host, limit, ok := c.limitFor(rawURL)
+	if !ok {
+		return nil
+	}
+
+	maxWait := c.config.MaxWait
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < maxWait {
+		maxWait = time.Until(deadline)
+	}
+	if maxWait < 0 {
+		maxWait = 0
+	}
+
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	interval := time.Duration(float64(time.Second) / limit.Rate)
+
+	wait, ok, err := c.config.Limiter.Reserve(ctx, c.config.KeyPrefix+host, interval, burst, maxWait)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.RateLimitedErr("too-many-requests-to-upstream-service", map[string]interface{}{
+			"host":        host,
+			"retry_in_ms": wait.Milliseconds(),
+		})
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c limits) limitFor(rawURL string) (host string, _ Limit, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", Limit{}, false
+	}
+
+	for _, host := range []string{u.Host, u.Hostname()} {
+		limit, ok := c.config.LimitsByHost[host]
+		if ok && limit.Rate > 0 {
+			return host, limit, true
+		}
+	}
+	return "", Limit{}, false
+}
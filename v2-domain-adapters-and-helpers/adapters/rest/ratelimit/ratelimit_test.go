@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	resthttp "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should allow bursts and then limit to the configured rate", func(t *testing.T) {
+		now := time.Date(2021, 5, 14, 10, 0, 0, 0, time.UTC)
+		limiter := NewMemoryLimiter()
+		limiter.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			wait, ok, err := limiter.Reserve(ctx, "fakeKey", time.Second, 3, 0)
+			tt.AssertNoErr(t, err)
+			tt.AssertTrue(t, ok)
+			tt.AssertEqual(t, wait, time.Duration(0))
+		}
+
+		wait, ok, err := limiter.Reserve(ctx, "fakeKey", time.Second, 3, 0)
+		tt.AssertNoErr(t, err)
+		tt.AssertFalse(t, ok)
+		tt.AssertEqual(t, wait, time.Second)
+
+		// Other keys have their own buckets:
+		_, ok, err = limiter.Reserve(ctx, "otherKey", time.Second, 3, 0)
+		tt.AssertNoErr(t, err)
+		tt.AssertTrue(t, ok)
+
+		// Waiting is allowed if within the maxWait:
+		wait, ok, err = limiter.Reserve(ctx, "fakeKey", time.Second, 3, 2*time.Second)
+		tt.AssertNoErr(t, err)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, wait, time.Second)
+
+		now = now.Add(2 * time.Second)
+		wait, ok, err = limiter.Reserve(ctx, "fakeKey", time.Second, 3, 0)
+		tt.AssertNoErr(t, err)
+		tt.AssertTrue(t, ok)
+		tt.AssertEqual(t, wait, time.Duration(0))
+	})
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int
+	mock := rest.Mock{
+		GetFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+			calls++
+			return rest.Response{StatusCode: 200}, nil
+		},
+	}
+
+	t.Run("should fail with RateLimitedErr when no tokens are available", func(t *testing.T) {
+		calls = 0
+		client := New(mock, Config{
+			LimitsByHost: map[string]Limit{
+				"fake.host": {Rate: 1, Burst: 2},
+			},
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+		}
+
+		_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "RateLimitedErr")
+		tt.AssertEqual(t, calls, 2)
+
+		// Hosts without limits are not affected:
+		_, err = client.Get(ctx, "http://other.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, calls, 3)
+	})
+
+	t.Run("should wait for a token when MaxWait allows it", func(t *testing.T) {
+		calls = 0
+		client := New(mock, Config{
+			LimitsByHost: map[string]Limit{
+				"fake.host": {Rate: 20},
+			},
+			MaxWait: time.Second,
+		})
+
+		startTime := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{})
+			tt.AssertNoErr(t, err)
+		}
+		tt.AssertEqual(t, calls, 3)
+		tt.AssertApproxDuration(t, 40*time.Millisecond, time.Since(startTime), 100*time.Millisecond, "unexpected wait time")
+	})
+}
+
+func TestTransport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should take one token for each attempt of the requests", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		serverURL, err := url.Parse(server.URL)
+		tt.AssertNoErr(t, err)
+
+		client := resthttp.NewWithConfig(resthttp.Config{
+			Timeout: time.Second,
+			Retry: resthttp.RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+			},
+			Transport: NewTransport(nil, Config{
+				LimitsByHost: map[string]Limit{
+					serverURL.Host: {Rate: 1, Burst: 2},
+				},
+			}),
+		})
+
+		// The third attempt is rejected by the limiter and not retried:
+		_, err = client.Get(ctx, server.URL, rest.RequestData{})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "RateLimitedErr")
+		tt.AssertEqual(t, requests, 2)
+	})
+}
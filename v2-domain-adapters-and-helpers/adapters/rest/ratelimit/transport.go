@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"net/http"
+)
+
+// Transport is an http.RoundTripper decorator that keeps the requests
+// sent to each host under the configured rate.
+//
+// Since it takes one token for each request actually sent, the retries
+// and hedged requests of the `adapters/rest/http` client are also counted,
+// so prefer it over the Client decorator when configuring that client.
+type Transport struct {
+	limits
+	next http.RoundTripper
+}
+
+// NewTransport instantiates a new rate limiter decorating the input
+// http.RoundTripper, if nil `http.DefaultTransport` is used.
+func NewTransport(next http.RoundTripper, config Config) Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return Transport{
+		limits: newLimits(config),
+		next:   next,
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := t.wait(req.Context(), req.URL.String())
+	if err != nil {
+		// RoundTrip must always close the request body:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,203 @@
+package restlogs
+
+import (
+	"context"
+	"io"
+	neturl "net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/metrics"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+)
+
+const (
+	// DurationMetric is the histogram of the request durations in seconds
+	DurationMetric = "rest_client_request_duration_seconds"
+
+	// SizeMetric is the histogram of the response body sizes in bytes
+	SizeMetric = "rest_client_response_size_bytes"
+)
+
+// SizeBuckets are reasonable buckets for the SizeMetric histogram
+var SizeBuckets = []float64{256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+
+// Client is a rest.Provider decorator that logs every outbound request
+// and records its duration and response size per upstream host.
+//
+// Both metrics are labeled by host, method and status, where the status
+// is the response status code or "error" if no response was received.
+type Client struct {
+	next   rest.Provider
+	config Config
+	now    func() time.Time
+}
+
+// Config describes the optional configurations of the decorator
+type Config struct {
+	// Logger receives one entry per request, on level DEBUG for successful
+	// requests and on level WARN for failed ones. Optional.
+	Logger log.Provider
+
+	// Metrics receives the duration and the size of each request. Optional.
+	Metrics metrics.Provider
+
+	// SensitiveQueryParams are redacted from the logs in addition to
+	// the ones listed on rest.SensitiveQueryParams
+	SensitiveQueryParams []string
+}
+
+// New instantiates a new logging decorator for the input rest.Provider
+func New(next rest.Provider, config Config) Client {
+	return Client{
+		next:   next,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Get implements the rest.Provider interface
+func (c Client) Get(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	startTime := c.now()
+	resp, err := c.next.Get(ctx, url, data)
+	c.report(ctx, "GET", url, data, startTime, resp.StatusCode, len(resp.Body), err)
+	return resp, err
+}
+
+// Post implements the rest.Provider interface
+func (c Client) Post(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	startTime := c.now()
+	resp, err := c.next.Post(ctx, url, data)
+	c.report(ctx, "POST", url, data, startTime, resp.StatusCode, len(resp.Body), err)
+	return resp, err
+}
+
+// Put implements the rest.Provider interface
+func (c Client) Put(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	startTime := c.now()
+	resp, err := c.next.Put(ctx, url, data)
+	c.report(ctx, "PUT", url, data, startTime, resp.StatusCode, len(resp.Body), err)
+	return resp, err
+}
+
+// Patch implements the rest.Provider interface
+func (c Client) Patch(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	startTime := c.now()
+	resp, err := c.next.Patch(ctx, url, data)
+	c.report(ctx, "PATCH", url, data, startTime, resp.StatusCode, len(resp.Body), err)
+	return resp, err
+}
+
+// Delete implements the rest.Provider interface
+func (c Client) Delete(ctx context.Context, url string, data rest.RequestData) (rest.Response, error) {
+	startTime := c.now()
+	resp, err := c.next.Delete(ctx, url, data)
+	c.report(ctx, "DELETE", url, data, startTime, resp.StatusCode, len(resp.Body), err)
+	return resp, err
+}
+
+// Stream implements the rest.Streamer interface, the request is only
+// reported when the body is closed, so the duration and the size
+// include the time spent reading the body.
+func (c Client) Stream(ctx context.Context, method string, url string, data rest.RequestData) (rest.StreamResponse, error) {
+	startTime := c.now()
+	resp, err := rest.Stream(ctx, c.next, method, url, data)
+	if resp.Body == nil {
+		c.report(ctx, method, url, data, startTime, resp.StatusCode, 0, err)
+		return resp, err
+	}
+
+	resp.Body = &reportingBody{
+		ReadCloser: resp.Body,
+		onClose: func(size int) {
+			c.report(ctx, method, url, data, startTime, resp.StatusCode, size, err)
+		},
+	}
+	return resp, err
+}
+
+// reportingBody counts the bytes read from the body
+// and calls onClose only once when it is closed
+type reportingBody struct {
+	io.ReadCloser
+	size    int
+	once    sync.Once
+	onClose func(size int)
+}
+
+func (b *reportingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += n
+	return n, err
+}
+
+func (b *reportingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.onClose(b.size)
+	})
+	return err
+}
+
+func (c Client) report(
+	ctx context.Context,
+	method string,
+	url string,
+	data rest.RequestData,
+	startTime time.Time,
+	statusCode int,
+	size int,
+	err error,
+) {
+	duration := c.now().Sub(startTime)
+
+	var host, path, query string
+	fullURL, parseErr := rest.AppendQuery(url, data.Query)
+	if parseErr == nil {
+		u, _ := neturl.Parse(rest.RedactURL(fullURL, c.config.SensitiveQueryParams...))
+		host, path, query = u.Host, u.Path, u.RawQuery
+	}
+
+	status := "error"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+
+	if c.config.Metrics != nil {
+		labels := metrics.Labels{
+			"host":   host,
+			"method": method,
+			"status": status,
+		}
+		c.config.Metrics.Observe(ctx, DurationMetric, duration.Seconds(), labels)
+		if statusCode != 0 {
+			c.config.Metrics.Observe(ctx, SizeMetric, float64(size), labels)
+		}
+	}
+
+	if c.config.Logger == nil {
+		return
+	}
+
+	body := log.Body{
+		"method":      method,
+		"host":        host,
+		"path":        path,
+		"status_code": statusCode,
+		"duration_ms": duration.Milliseconds(),
+		"bytes":       size,
+	}
+	if query != "" {
+		body["query"] = query
+	}
+
+	if err != nil {
+		// Errors from the rest adapter are already redacted:
+		body["error"] = err.Error()
+		c.config.Logger.Warn(ctx, "outbound-rest-request-failed", body)
+		return
+	}
+	c.config.Logger.Debug(ctx, "outbound-rest-request", body)
+}
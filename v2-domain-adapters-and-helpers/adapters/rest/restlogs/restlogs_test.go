@@ -0,0 +1,148 @@
+package restlogs
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/metrics"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	type logEntry struct {
+		level string
+		title string
+		body  log.Body
+	}
+
+	type observation struct {
+		name   string
+		value  float64
+		labels metrics.Labels
+	}
+
+	newClient := func(next rest.Provider, logs *[]logEntry, observations *[]observation) Client {
+		client := New(next, Config{
+			Logger: log.Mock{
+				DebugFn: func(ctx context.Context, title string, valueMaps ...log.Body) {
+					*logs = append(*logs, logEntry{"DEBUG", title, valueMaps[0]})
+				},
+				WarnFn: func(ctx context.Context, title string, valueMaps ...log.Body) {
+					*logs = append(*logs, logEntry{"WARN", title, valueMaps[0]})
+				},
+			},
+			Metrics: metrics.Mock{
+				ObserveFn: func(ctx context.Context, name string, value float64, labels metrics.Labels) {
+					*observations = append(*observations, observation{name, value, labels})
+				},
+			},
+		})
+
+		// Each call to now advances the clock by 250ms:
+		now := time.Date(2021, 5, 14, 10, 0, 0, 0, time.UTC)
+		client.now = func() time.Time {
+			now = now.Add(250 * time.Millisecond)
+			return now
+		}
+		return client
+	}
+
+	t.Run("should log and measure successful requests without leaking secrets", func(t *testing.T) {
+		var logs []logEntry
+		var observations []observation
+		client := newClient(rest.Mock{
+			GetFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+				return rest.Response{StatusCode: 200, Body: []byte("fakeBody")}, nil
+			},
+		}, &logs, &observations)
+
+		_, err := client.Get(ctx, "http://fake.host/foo", rest.RequestData{
+			Query: url.Values{
+				"client_secret": {"fakeSecret"},
+			},
+		})
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, logs, []logEntry{{
+			level: "DEBUG",
+			title: "outbound-rest-request",
+			body: log.Body{
+				"method":      "GET",
+				"host":        "fake.host",
+				"path":        "/foo",
+				"query":       "client_secret=REDACTED",
+				"status_code": 200,
+				"duration_ms": int64(250),
+				"bytes":       8,
+			},
+		}})
+
+		labels := metrics.Labels{"host": "fake.host", "method": "GET", "status": "200"}
+		tt.AssertEqual(t, observations, []observation{
+			{name: DurationMetric, value: 0.25, labels: labels},
+			{name: SizeMetric, value: 8, labels: labels},
+		})
+	})
+
+	t.Run("should report network errors with the error status", func(t *testing.T) {
+		var logs []logEntry
+		var observations []observation
+		client := newClient(rest.Mock{
+			PostFn: func(ctx context.Context, url string, data rest.RequestData) (resp rest.Response, err error) {
+				return rest.Response{}, context.DeadlineExceeded
+			},
+		}, &logs, &observations)
+
+		_, err := client.Post(ctx, "http://fake.host/foo", rest.RequestData{})
+		tt.AssertEqual(t, err, context.DeadlineExceeded)
+
+		tt.AssertEqual(t, len(logs), 1)
+		tt.AssertEqual(t, logs[0].level, "WARN")
+		tt.AssertEqual(t, logs[0].body["error"], "context deadline exceeded")
+
+		tt.AssertEqual(t, observations, []observation{
+			{name: DurationMetric, value: 0.25, labels: metrics.Labels{"host": "fake.host", "method": "POST", "status": "error"}},
+		})
+	})
+
+	t.Run("should report streamed requests when the body is closed", func(t *testing.T) {
+		var logs []logEntry
+		var observations []observation
+		client := newClient(rest.Mock{
+			StreamFn: func(ctx context.Context, method string, url string, data rest.RequestData) (resp rest.StreamResponse, err error) {
+				return rest.StreamResponse{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader("fakeBody")),
+				}, nil
+			},
+		}, &logs, &observations)
+
+		resp, err := client.Stream(ctx, "GET", "http://fake.host/foo", rest.RequestData{})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(logs), 0)
+
+		body, err := io.ReadAll(resp.Body)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, string(body), "fakeBody")
+
+		tt.AssertNoErr(t, resp.Body.Close())
+		tt.AssertNoErr(t, resp.Body.Close())
+
+		tt.AssertEqual(t, len(logs), 1)
+		tt.AssertEqual(t, logs[0].body["bytes"], 8)
+
+		labels := metrics.Labels{"host": "fake.host", "method": "GET", "status": "200"}
+		tt.AssertEqual(t, observations, []observation{
+			{name: DurationMetric, value: 0.25, labels: labels},
+			{name: SizeMetric, value: 8, labels: labels},
+		})
+	})
+}
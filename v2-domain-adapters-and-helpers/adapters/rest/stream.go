@@ -0,0 +1,19 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stream forwards the request to the Stream method of the input provider,
+// it is meant for the decorators, so they can implement the Streamer
+// interface regardless of the provider they are decorating.
+//
+// It returns an error if the provider doesn't implement the Streamer interface.
+func Stream(ctx context.Context, p Provider, method string, url string, data RequestData) (StreamResponse, error) {
+	streamer, ok := p.(Streamer)
+	if !ok {
+		return StreamResponse{}, fmt.Errorf("rest provider %T does not support streaming", p)
+	}
+	return streamer.Stream(ctx, method, url, data)
+}
@@ -0,0 +1,17 @@
+package venue
+
+import (
+	"context"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Provider represents the operations we use for
+// retrieving venues from an upstream source, e.g. Foursquare
+type Provider interface {
+	// GetVenues searches the venues around a point, the optional filters
+	// that are not supported by the upstream are ignored.
+	GetVenues(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error)
+
+	GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error)
+}
@@ -0,0 +1,200 @@
+package foursquare
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Client implements the venue.Provider interface using
+// the v2 API of Foursquare, e.g. "https://api.foursquare.com/v2"
+type Client struct {
+	rest rest.Provider
+
+	baseURL  string
+	clientID string
+	secret   string
+}
+
+// New instantiates a new Foursquare client
+func New(rest rest.Provider, baseURL string, clientID string, secret string) Client {
+	return Client{
+		rest:     rest,
+		baseURL:  baseURL,
+		clientID: clientID,
+		secret:   secret,
+	}
+}
+
+type searchResponse struct {
+	Meta struct {
+		Code      int    `json:"code"`
+		RequestID string `json:"requestId"`
+	}
+	Response struct {
+		Venues []domain.Venue `json:"venues"`
+	} `json:"response"`
+}
+
+// GetVenues implements the venue.Provider interface,
+// the OpenNow filter is not supported by the v2 search endpoint.
+func (c Client) GetVenues(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+	query := url.Values{
+		"ll": {params.Coordinates.String()},
+	}
+	if params.Radius > 0 {
+		// The radius is only respected when browsing an area:
+		query.Set("intent", "browse")
+		query.Set("radius", strconv.Itoa(params.Radius))
+	}
+	if params.Query != "" {
+		query.Set("query", params.Query)
+	}
+	if len(params.CategoryIDs) > 0 {
+		query.Set("categoryId", strings.Join(params.CategoryIDs, ","))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+
+	respBody, err := rest.GetJSON[searchResponse](ctx, c.rest, c.baseURL+"/venues/search", rest.RequestData{
+		Query: c.authParams(query),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return respBody.Response.Venues, nil
+}
+
+type detailsResponse struct {
+	Response struct {
+		Venue struct {
+			ID          string          `json:"id"`
+			Name        string          `json:"name"`
+			Description string          `json:"description"`
+			Location    domain.Location `json:"location"`
+			Categories  []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"categories"`
+			Rating  float64 `json:"rating"`
+			URL     string  `json:"url"`
+			Contact struct {
+				FormattedPhone string `json:"formattedPhone"`
+				Phone          string `json:"phone"`
+				Twitter        string `json:"twitter"`
+			} `json:"contact"`
+			Hours *struct {
+				Status     string `json:"status"`
+				IsOpen     bool   `json:"isOpen"`
+				Timeframes []struct {
+					Days string `json:"days"`
+					Open []struct {
+						RenderedTime string `json:"renderedTime"`
+					} `json:"open"`
+				} `json:"timeframes"`
+			} `json:"hours"`
+			BestPhoto *photo `json:"bestPhoto"`
+			Photos    struct {
+				Groups []struct {
+					Items []photo `json:"items"`
+				} `json:"groups"`
+			} `json:"photos"`
+		} `json:"venue"`
+	} `json:"response"`
+}
+
+type photo struct {
+	Prefix string `json:"prefix"`
+	Suffix string `json:"suffix"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// GetVenue implements the venue.Provider interface
+func (c Client) GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+	respBody, err := rest.GetJSON[detailsResponse](ctx, c.rest, c.baseURL+"/venues/"+url.PathEscape(venueID), rest.RequestData{
+		Query: c.authParams(url.Values{}),
+	})
+	if err != nil {
+		return domain.VenueDetails{}, err
+	}
+	v := respBody.Response.Venue
+
+	details := domain.VenueDetails{
+		ID:          v.ID,
+		Name:        v.Name,
+		Description: v.Description,
+		Location:    v.Location,
+		Rating:      v.Rating,
+		Contact: domain.Contact{
+			Phone:   v.Contact.FormattedPhone,
+			Website: v.URL,
+			Twitter: v.Contact.Twitter,
+		},
+	}
+	if details.Contact.Phone == "" {
+		details.Contact.Phone = v.Contact.Phone
+	}
+
+	for _, category := range v.Categories {
+		details.Categories = append(details.Categories, domain.Category{
+			ID:   category.ID,
+			Name: category.Name,
+		})
+	}
+
+	// The v2 API only describes the hours as rendered text:
+	if v.Hours != nil {
+		isOpen := v.Hours.IsOpen
+		details.Hours.OpenNow = &isOpen
+
+		var timeframes []string
+		for _, timeframe := range v.Hours.Timeframes {
+			var times []string
+			for _, open := range timeframe.Open {
+				times = append(times, open.RenderedTime)
+			}
+			timeframes = append(timeframes, timeframe.Days+" "+strings.Join(times, ", "))
+		}
+		details.Hours.Display = strings.Join(timeframes, "; ")
+		if details.Hours.Display == "" {
+			details.Hours.Display = v.Hours.Status
+		}
+	}
+
+	if v.BestPhoto != nil {
+		details.Photos = append(details.Photos, v.BestPhoto.toPhoto())
+	}
+	for _, group := range v.Photos.Groups {
+		for _, item := range group.Items {
+			if v.BestPhoto != nil && item.Suffix == v.BestPhoto.Suffix {
+				continue
+			}
+			details.Photos = append(details.Photos, item.toPhoto())
+		}
+	}
+
+	return details, nil
+}
+
+func (p photo) toPhoto() domain.Photo {
+	return domain.Photo{
+		URL:    p.Prefix + "original" + p.Suffix,
+		Width:  p.Width,
+		Height: p.Height,
+	}
+}
+
+// authParams adds the query params required by all foursquare requests
+func (c Client) authParams(query url.Values) url.Values {
+	query.Set("client_id", c.clientID)
+	query.Set("client_secret", c.secret)
+	query.Set("v", "20210514")
+	return query
+}
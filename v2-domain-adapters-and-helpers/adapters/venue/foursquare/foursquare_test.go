@@ -0,0 +1,124 @@
+package foursquare
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/cassette"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+// newFoursquareCassette replays the interactions saved on `testdata/foursquare.json`,
+// to record them again against the real API run the tests with:
+//
+//	CASSETTE_MODE=record FOURSQUARE_CLIENT_ID=<id> FOURSQUARE_SECRET=<secret> go test ./adapters/venue/foursquare/...
+func newFoursquareCassette(t *testing.T) Client {
+	mode := cassette.Mode(os.Getenv("CASSETTE_MODE"))
+
+	clientID, secret := "fakeClientID", "fakeSecret"
+	if mode == cassette.ModeRecord {
+		clientID = os.Getenv("FOURSQUARE_CLIENT_ID")
+		secret = os.Getenv("FOURSQUARE_SECRET")
+	}
+
+	rest, err := cassette.New(http.New(30*time.Second), cassette.Config{
+		Mode: mode,
+		Path: "testdata/foursquare.json",
+	})
+	tt.AssertNoErr(t, err)
+
+	t.Cleanup(func() {
+		tt.AssertNoErr(t, rest.Save())
+	})
+
+	return New(rest, "https://api.foursquare.com/v2", clientID, secret)
+}
+
+func TestGetVenues(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should parse the venues returned by foursquare", func(t *testing.T) {
+		client := newFoursquareCassette(t)
+
+		venues, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, len(venues), 2)
+		tt.AssertEqual(t, venues[0].ID, "43695300f964a5208c291fe3")
+		tt.AssertEqual(t, venues[0].Name, "Empire State Building")
+		tt.AssertEqual(t, venues[0].Location.Address, "350 5th Ave")
+		tt.AssertEqual(t, venues[0].Location.Distance, 4)
+		tt.AssertEqual(t, venues[1].ID, "4b7efa2ef964a520580130e3")
+	})
+}
+
+func TestGetVenue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should fetch the venue details", func(t *testing.T) {
+		client := newFoursquareCassette(t)
+
+		venue, err := client.GetVenue(ctx, "43695300f964a5208c291fe3")
+		tt.AssertNoErr(t, err)
+
+		isOpen := true
+		tt.AssertEqual(t, venue, domain.VenueDetails{
+			ID:   "43695300f964a5208c291fe3",
+			Name: "Empire State Building",
+			Location: domain.Location{
+				Address:          "350 5th Ave",
+				CrossStreet:      "at W 34th St",
+				Latitude:         40.748442,
+				Longitude:        -73.985658,
+				PostalCode:       "10118",
+				CountryCode:      "US",
+				City:             "New York",
+				State:            "NY",
+				Country:          "United States",
+				FormattedAddress: []string{"350 5th Ave (at W 34th St)", "New York, NY 10118", "United States"},
+			},
+			Categories: []domain.Category{
+				{ID: "4bf58dd8d48988d130941735", Name: "Building"},
+			},
+			Rating: 9.4,
+			Hours: domain.OpeningHours{
+				Display: "Mon–Sun 8:00 AM–2:00 AM",
+				OpenNow: &isOpen,
+			},
+			Contact: domain.Contact{
+				Phone:   "(212) 736-3100",
+				Website: "https://www.esbnyc.com",
+				Twitter: "empirestatebldg",
+			},
+			Photos: []domain.Photo{{
+				URL:    "https://fastly.4sqi.net/img/general/original/3556_Mh5N1wD9pZuQ8t7ZAYx3mm7qmxqR5KE8VwA0oWvK2Ys.jpg",
+				Width:  1440,
+				Height: 1920,
+			}},
+		})
+	})
+
+	t.Run("should report errors from foursquare without leaking secrets", func(t *testing.T) {
+		client := newFoursquareCassette(t)
+
+		_, err := client.GetVenue(ctx, "missingVenueID")
+		tt.AssertErrContains(t, err, "400", "missingVenueID", "client_secret=REDACTED")
+	})
+
+	t.Run("should fail on requests that were not recorded", func(t *testing.T) {
+		if os.Getenv("CASSETTE_MODE") == string(cassette.ModeRecord) {
+			t.Skip("only meaningful on replay mode")
+		}
+
+		client := newFoursquareCassette(t)
+
+		_, err := client.GetVenue(ctx, "notRecordedVenueID")
+		tt.AssertErrContains(t, err, "cassette", "no recorded interaction", "notRecordedVenueID")
+	})
+}
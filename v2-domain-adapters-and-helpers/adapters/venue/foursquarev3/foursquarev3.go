@@ -0,0 +1,334 @@
+package foursquarev3
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/auth"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Client implements the venue.Provider interface using the
+// Places API (v3) of Foursquare, e.g. "https://api.foursquare.com/v3"
+type Client struct {
+	rest    rest.Provider
+	baseURL string
+	config  Config
+}
+
+// Config describes the optional configurations of the client
+type Config struct {
+	// SearchFields are the fields requested on the searches,
+	// only the default ones are used for building the venues.
+	SearchFields []string
+
+	// DetailsFields are the fields requested for the details of a venue.
+	DetailsFields []string
+
+	// Limit is the number of places per page, the API allows at most 50.
+	// Defaults to 50.
+	Limit int
+
+	// MaxPages is the maximum number of pages fetched on each search
+	// by following the `Link` headers. Defaults to 1.
+	MaxPages int
+}
+
+// New instantiates a new Foursquare Places client,
+// the apiKey is sent on the Authorization header of all requests.
+func New(restClient rest.Provider, baseURL string, apiKey string, config Config) Client {
+	if config.SearchFields == nil {
+		config.SearchFields = []string{"fsq_id", "name", "geocodes", "location", "distance"}
+	}
+	if config.DetailsFields == nil {
+		config.DetailsFields = []string{
+			"fsq_id", "name", "geocodes", "location", "categories", "chains",
+			"description", "tel", "website", "hours", "rating", "photos",
+		}
+	}
+	if config.Limit <= 0 || config.Limit > 50 {
+		config.Limit = 50
+	}
+	if config.MaxPages <= 0 {
+		config.MaxPages = 1
+	}
+
+	return Client{
+		rest:    auth.NewAPIKey(restClient, "Authorization", apiKey),
+		baseURL: baseURL,
+		config:  config,
+	}
+}
+
+type searchResponse struct {
+	Results []place `json:"results"`
+}
+
+type place struct {
+	ID       string `json:"fsq_id"`
+	Name     string `json:"name"`
+	Distance int    `json:"distance"`
+	Geocodes struct {
+		Main struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"main"`
+		Roof *struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"roof"`
+	} `json:"geocodes"`
+	Location struct {
+		Address          string `json:"address"`
+		CrossStreet      string `json:"cross_street"`
+		Postcode         string `json:"postcode"`
+		Country          string `json:"country"`
+		Locality         string `json:"locality"`
+		Region           string `json:"region"`
+		FormattedAddress string `json:"formatted_address"`
+	} `json:"location"`
+}
+
+// GetVenues implements the venue.Provider interface
+func (c Client) GetVenues(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+	limit := c.config.Limit
+	if params.Limit > 0 && params.Limit < limit {
+		limit = params.Limit
+	}
+
+	nextURL := c.baseURL + "/places/search"
+	query := url.Values{
+		"ll":     {params.Coordinates.String()},
+		"fields": {strings.Join(c.config.SearchFields, ",")},
+		"limit":  {strconv.Itoa(limit)},
+	}
+	if params.Radius > 0 {
+		query.Set("radius", strconv.Itoa(params.Radius))
+	}
+	if params.Query != "" {
+		query.Set("query", params.Query)
+	}
+	if len(params.CategoryIDs) > 0 {
+		query.Set("categories", strings.Join(params.CategoryIDs, ","))
+	}
+	if params.OpenNow {
+		query.Set("open_now", "true")
+	}
+
+	venues := []domain.Venue{}
+	for page := 0; page < c.config.MaxPages && nextURL != ""; page++ {
+		resp, err := c.rest.Get(ctx, nextURL, rest.RequestData{
+			Query: query,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed searchResponse
+		err = json.Unmarshal(resp.Body, &parsed)
+		if err != nil {
+			return nil, domain.InternalErr("unable-to-parse-upstream-response-as-json", map[string]interface{}{
+				"url":         rest.RedactURL(nextURL),
+				"status_code": resp.StatusCode,
+				"error":       err.Error(),
+				"payload":     string(resp.Body),
+			})
+		}
+
+		for _, p := range parsed.Results {
+			venues = append(venues, p.toVenue())
+		}
+		if params.Limit > 0 && len(venues) >= params.Limit {
+			return venues[:params.Limit], nil
+		}
+
+		// The next link already carries all the query params, including the cursor:
+		nextURL = nextLink(resp.Header)
+		query = nil
+
+		// The API key is sent on every request, so we never follow links to other hosts:
+		if nextURL != "" && !isSameOrigin(nextURL, c.baseURL) {
+			return nil, domain.InternalErr("upstream-returned-next-link-to-another-origin", map[string]interface{}{
+				"url":       rest.RedactURL(nextURL),
+				"base_url":  c.baseURL,
+				"next_page": page + 1,
+			})
+		}
+	}
+
+	return venues, nil
+}
+
+type placeDetails struct {
+	place
+
+	Description string `json:"description"`
+	Categories  []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"categories"`
+	Rating      float64 `json:"rating"`
+	Tel         string  `json:"tel"`
+	Website     string  `json:"website"`
+	Email       string  `json:"email"`
+	SocialMedia struct {
+		Twitter string `json:"twitter"`
+	} `json:"social_media"`
+	Hours *struct {
+		Display string `json:"display"`
+		OpenNow *bool  `json:"open_now"`
+		Regular []struct {
+			Day   int    `json:"day"`
+			Open  string `json:"open"`
+			Close string `json:"close"`
+		} `json:"regular"`
+	} `json:"hours"`
+	Photos []struct {
+		Prefix string `json:"prefix"`
+		Suffix string `json:"suffix"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	} `json:"photos"`
+}
+
+// GetVenue implements the venue.Provider interface
+func (c Client) GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+	p, err := rest.GetJSON[placeDetails](ctx, c.rest, c.baseURL+"/places/"+url.PathEscape(venueID), rest.RequestData{
+		Query: url.Values{
+			"fields": {strings.Join(c.config.DetailsFields, ",")},
+		},
+	})
+	if err != nil {
+		return domain.VenueDetails{}, err
+	}
+
+	venue := p.toVenue()
+	details := domain.VenueDetails{
+		ID:          venue.ID,
+		Name:        venue.Name,
+		Description: p.Description,
+		Location:    venue.Location,
+		Rating:      p.Rating,
+		Contact: domain.Contact{
+			Phone:   p.Tel,
+			Website: p.Website,
+			Email:   p.Email,
+			Twitter: p.SocialMedia.Twitter,
+		},
+	}
+
+	for _, category := range p.Categories {
+		details.Categories = append(details.Categories, domain.Category{
+			ID:   strconv.Itoa(category.ID),
+			Name: category.Name,
+		})
+	}
+
+	if p.Hours != nil {
+		details.Hours.Display = p.Hours.Display
+		details.Hours.OpenNow = p.Hours.OpenNow
+		for _, period := range p.Hours.Regular {
+			details.Hours.Periods = append(details.Hours.Periods, domain.OpeningPeriod{
+				Day:   period.Day,
+				Open:  period.Open,
+				Close: period.Close,
+			})
+		}
+	}
+
+	for _, photo := range p.Photos {
+		details.Photos = append(details.Photos, domain.Photo{
+			URL:    photo.Prefix + "original" + photo.Suffix,
+			Width:  photo.Width,
+			Height: photo.Height,
+		})
+	}
+
+	return details, nil
+}
+
+func (p place) toVenue() domain.Venue {
+	var formattedAddress []string
+	if p.Location.FormattedAddress != "" {
+		formattedAddress = []string{p.Location.FormattedAddress}
+	}
+
+	var labeledCoords []domain.LabeledCoords
+	if p.Geocodes.Roof != nil {
+		labeledCoords = append(labeledCoords, domain.LabeledCoords{
+			Label: "roof",
+			Lat:   p.Geocodes.Roof.Latitude,
+			Lng:   p.Geocodes.Roof.Longitude,
+		})
+	}
+
+	return domain.Venue{
+		ID:   p.ID,
+		Name: p.Name,
+		Location: domain.Location{
+			Address:          p.Location.Address,
+			CrossStreet:      p.Location.CrossStreet,
+			Latitude:         p.Geocodes.Main.Latitude,
+			Longitude:        p.Geocodes.Main.Longitude,
+			LabeledLatLngs:   labeledCoords,
+			Distance:         p.Distance,
+			PostalCode:       p.Location.Postcode,
+			CountryCode:      p.Location.Country,
+			City:             p.Location.Locality,
+			State:            p.Location.Region,
+			FormattedAddress: formattedAddress,
+		},
+	}
+}
+
+// nextLink returns the URL of the `rel="next"` entry of the Link header, e.g.:
+//
+//	Link: <https://api.foursquare.com/v3/places/search?ll=1,1&cursor=c3I6MjA>; rel="next"
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		// The URLs may contain commas, e.g. on the `ll` param, so
+		// the entries are split on the brackets instead of on the commas:
+		for {
+			start := strings.Index(value, "<")
+			end := strings.Index(value, ">")
+			if start == -1 || end < start {
+				break
+			}
+
+			target := value[start+1 : end]
+			value = value[end+1:]
+
+			params := value
+			if i := strings.Index(value, "<"); i != -1 {
+				params = value[:i]
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, paramValue, _ := strings.Cut(strings.Trim(param, " ,"), "=")
+				if key == "rel" && strings.Trim(paramValue, `"`) == "next" {
+					return target
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// isSameOrigin checks if both URLs have the same scheme and host
+func isSameOrigin(rawURL string, baseURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Scheme, base.Scheme) && strings.EqualFold(u.Host, base.Host)
+}
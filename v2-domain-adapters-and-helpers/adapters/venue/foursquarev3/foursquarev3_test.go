@@ -0,0 +1,270 @@
+package foursquarev3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	resthttp "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+// newFixtureServer serves the files on testdata/ the same way the Places API would,
+// the search is split in two pages linked by the `Link` header.
+func newFixtureServer(t *testing.T, receivedRequests *[]*http.Request) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*receivedRequests = append(*receivedRequests, r)
+
+		if r.Header.Get("Authorization") != "fakeAPIKey" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Invalid request token."}`))
+			return
+		}
+
+		var file string
+		switch r.URL.Path {
+		case "/places/search":
+			file = "testdata/search_page1.json"
+			if r.URL.Query().Get("cursor") == "fakeCursor" {
+				file = "testdata/search_page2.json"
+			} else {
+				w.Header().Set("Link", `<`+server.URL+`/places/search?ll=40.7484,-73.9857&cursor=fakeCursor>; rel="next"`)
+			}
+		case "/places/43695300f964a5208c291fe3":
+			file = "testdata/details.json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+
+		body, err := os.ReadFile(file)
+		tt.AssertNoErr(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetVenues(t *testing.T) {
+	ctx := context.Background()
+
+	empireState := domain.Venue{
+		ID:   "43695300f964a5208c291fe3",
+		Name: "Empire State Building",
+		Location: domain.Location{
+			Address:     "350 5th Ave",
+			CrossStreet: "at W 34th St",
+			Latitude:    40.748441,
+			Longitude:   -73.985664,
+			LabeledLatLngs: []domain.LabeledCoords{
+				{Label: "roof", Lat: 40.748441, Lng: -73.985664},
+			},
+			Distance:         6,
+			PostalCode:       "10118",
+			CountryCode:      "US",
+			City:             "New York",
+			State:            "NY",
+			FormattedAddress: []string{"350 5th Ave (at W 34th St), New York, NY 10118"},
+		},
+	}
+
+	t.Run("should convert the places into venues", func(t *testing.T) {
+		var requests []*http.Request
+		server := newFixtureServer(t, &requests)
+
+		client := New(resthttp.New(time.Second), server.URL, "fakeAPIKey", Config{})
+
+		venues, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, venues, []domain.Venue{empireState})
+
+		tt.AssertEqual(t, len(requests), 1)
+		query := requests[0].URL.Query()
+		tt.AssertEqual(t, query.Get("ll"), "40.7484,-73.9857")
+		tt.AssertEqual(t, query.Get("fields"), "fsq_id,name,geocodes,location,distance")
+		tt.AssertEqual(t, query.Get("limit"), "50")
+	})
+
+	t.Run("should map the search params into the request", func(t *testing.T) {
+		var requests []*http.Request
+		server := newFixtureServer(t, &requests)
+
+		client := New(resthttp.New(time.Second), server.URL, "fakeAPIKey", Config{})
+
+		_, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      500,
+			Query:       "pizza",
+			CategoryIDs: []string{"13064", "13065"},
+			Limit:       10,
+			OpenNow:     true,
+		})
+		tt.AssertNoErr(t, err)
+
+		query := requests[0].URL.Query()
+		tt.AssertEqual(t, query.Get("radius"), "500")
+		tt.AssertEqual(t, query.Get("query"), "pizza")
+		tt.AssertEqual(t, query.Get("categories"), "13064,13065")
+		tt.AssertEqual(t, query.Get("limit"), "10")
+		tt.AssertEqual(t, query.Get("open_now"), "true")
+	})
+
+	t.Run("should follow the Link headers up to MaxPages", func(t *testing.T) {
+		var requests []*http.Request
+		server := newFixtureServer(t, &requests)
+
+		client := New(resthttp.New(time.Second), server.URL, "fakeAPIKey", Config{
+			MaxPages: 3,
+		})
+
+		venues, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(requests), 2)
+		tt.AssertEqual(t, len(venues), 2)
+		tt.AssertEqual(t, venues[0], empireState)
+		tt.AssertEqual(t, venues[1].ID, "4b7efa2ef964a520580130e3")
+		tt.AssertEqual(t, venues[1].Location.LabeledLatLngs, []domain.LabeledCoords(nil))
+		tt.AssertEqual(t, requests[1].URL.Query().Get("cursor"), "fakeCursor")
+	})
+
+	t.Run("should not follow Link headers to other hosts", func(t *testing.T) {
+		var otherRequests []*http.Request
+		otherServer := newFixtureServer(t, &otherRequests)
+
+		var requests []*http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+
+			body, err := os.ReadFile("testdata/search_page1.json")
+			tt.AssertNoErr(t, err)
+			w.Header().Set("Link", `<`+otherServer.URL+`/places/search?cursor=fakeCursor>; rel="next"`)
+			w.Write(body)
+		}))
+		defer server.Close()
+
+		client := New(resthttp.New(time.Second), server.URL, "fakeAPIKey", Config{
+			MaxPages: 3,
+		})
+
+		_, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertErrContains(t, err, "upstream-returned-next-link-to-another-origin")
+		tt.AssertEqual(t, len(requests), 1)
+		tt.AssertEqual(t, len(otherRequests), 0)
+	})
+
+	t.Run("should report authentication errors", func(t *testing.T) {
+		var requests []*http.Request
+		server := newFixtureServer(t, &requests)
+
+		client := New(resthttp.New(time.Second), server.URL, "wrongAPIKey", Config{})
+
+		_, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertErrContains(t, err, "401")
+	})
+}
+
+func TestGetVenue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return the details with the selected fields", func(t *testing.T) {
+		var requests []*http.Request
+		server := newFixtureServer(t, &requests)
+
+		client := New(resthttp.New(time.Second), server.URL, "fakeAPIKey", Config{
+			DetailsFields: []string{"fsq_id", "name", "rating"},
+		})
+
+		venue, err := client.GetVenue(ctx, "43695300f964a5208c291fe3")
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, requests[0].URL.Query().Get("fields"), "fsq_id,name,rating")
+
+		openNow := true
+		tt.AssertEqual(t, venue, domain.VenueDetails{
+			ID:          "43695300f964a5208c291fe3",
+			Name:        "Empire State Building",
+			Description: "Iconic 102-story skyscraper with observation decks.",
+			Location: domain.Location{
+				Address:          "350 5th Ave",
+				Latitude:         40.748441,
+				Longitude:        -73.985664,
+				PostalCode:       "10118",
+				CountryCode:      "US",
+				City:             "New York",
+				State:            "NY",
+				FormattedAddress: []string{"350 5th Ave (at W 34th St), New York, NY 10118"},
+			},
+			Categories: []domain.Category{
+				{ID: "16020", Name: "Historic and Protected Site"},
+			},
+			Rating: 9.4,
+			Hours: domain.OpeningHours{
+				Display: "Mon-Sun 8:00 AM-2:00 AM",
+				OpenNow: &openNow,
+				Periods: []domain.OpeningPeriod{
+					{Day: 1, Open: "0800", Close: "0200"},
+					{Day: 2, Open: "0800", Close: "0200"},
+				},
+			},
+			Contact: domain.Contact{
+				Phone:   "(212) 736-3100",
+				Website: "https://www.esbnyc.com",
+				Email:   "info@esbnyc.com",
+				Twitter: "empirestatebldg",
+			},
+			Photos: []domain.Photo{{
+				URL:    "https://fastly.4sqi.net/img/general/original/3556_Mh5N1wD9pZuQ8t7ZAYx3mm7qmxqR5KE8VwA0oWvK2Ys.jpg",
+				Width:  1440,
+				Height: 1920,
+			}},
+		})
+	})
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		desc     string
+		header   http.Header
+		expected string
+	}{
+		{
+			desc:     "should return empty when there is no Link header",
+			header:   http.Header{},
+			expected: "",
+		},
+		{
+			desc: "should find the next link among other links",
+			header: http.Header{
+				"Link": {`<https://fake.host/v3/places/search?ll=1,1&cursor=prev>; rel="prev", <https://fake.host/v3/places/search?ll=1,1&cursor=next>; rel="next"`},
+			},
+			expected: "https://fake.host/v3/places/search?ll=1,1&cursor=next",
+		},
+		{
+			desc: "should ignore links with other relations",
+			header: http.Header{
+				"Link": {`<https://fake.host/v3/places/search?cursor=prev>; rel="prev"`},
+			},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			tt.AssertEqual(t, nextLink(test.header), test.expected)
+		})
+	}
+}
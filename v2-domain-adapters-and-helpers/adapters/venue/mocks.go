@@ -0,0 +1,20 @@
+package venue
+
+import (
+	"context"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+type Mock struct {
+	GetVenuesFn func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error)
+	GetVenueFn  func(ctx context.Context, venueID string) (domain.VenueDetails, error)
+}
+
+func (m Mock) GetVenues(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+	return m.GetVenuesFn(ctx, params)
+}
+
+func (m Mock) GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+	return m.GetVenueFn(ctx, venueID)
+}
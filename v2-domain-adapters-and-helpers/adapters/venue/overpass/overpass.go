@@ -0,0 +1,240 @@
+package overpass
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// Client implements the venue.Provider interface using the OpenStreetMap
+// data served by an Overpass API, e.g. "https://overpass-api.de/api"
+//
+// The IDs of the venues are built from the OpenStreetMap element type and ID,
+// e.g. "node-2709306673" or "way-34633854".
+type Client struct {
+	rest    rest.Provider
+	baseURL string
+	config  Config
+}
+
+// Config describes the optional configurations of the client
+type Config struct {
+	// Radius of the searches in meters. Defaults to 250.
+	Radius int
+
+	// Limit is the maximum number of venues returned by a search. Defaults to 50.
+	Limit int
+
+	// Keys lists the OpenStreetMap tags that identify a venue,
+	// any named element with one of these tags is returned.
+	//
+	// Defaults to "amenity", "shop", "tourism" and "leisure".
+	Keys []string
+}
+
+// New instantiates a new Overpass client
+func New(rest rest.Provider, baseURL string, config Config) Client {
+	if config.Radius <= 0 {
+		config.Radius = 250
+	}
+	if config.Limit <= 0 {
+		config.Limit = 50
+	}
+	if config.Keys == nil {
+		config.Keys = []string{"amenity", "shop", "tourism", "leisure"}
+	}
+
+	return Client{
+		rest:    rest,
+		baseURL: baseURL,
+		config:  config,
+	}
+}
+
+type response struct {
+	Elements []element `json:"elements"`
+}
+
+type element struct {
+	Type   string            `json:"type"`
+	ID     int64             `json:"id"`
+	Lat    float64           `json:"lat"`
+	Lon    float64           `json:"lon"`
+	Center *coords           `json:"center"`
+	Tags   map[string]string `json:"tags"`
+}
+
+type coords struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// GetVenues implements the venue.Provider interface
+//
+// The CategoryIDs are OpenStreetMap tags, e.g. "amenity=restaurant", or
+// only tag values, e.g. "restaurant", matched against the configured keys.
+// The OpenNow filter is not supported.
+func (c Client) GetVenues(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+	radius := c.config.Radius
+	if params.Radius > 0 {
+		radius = params.Radius
+	}
+	limit := c.config.Limit
+	if params.Limit > 0 {
+		limit = params.Limit
+	}
+	around := fmt.Sprintf("around:%d,%s", radius, params.Coordinates)
+
+	nameFilter := `["name"]`
+	if params.Query != "" {
+		nameFilter = `["name"~` + quote(regexp.QuoteMeta(params.Query)) + `,i]`
+	}
+
+	var tagFilters []string
+	for _, key := range c.config.Keys {
+		if len(params.CategoryIDs) == 0 {
+			tagFilters = append(tagFilters, "["+quote(key)+"]")
+		}
+		for _, category := range params.CategoryIDs {
+			if !strings.Contains(category, "=") {
+				tagFilters = append(tagFilters, "["+quote(key)+"="+quote(category)+"]")
+			}
+		}
+	}
+	for _, category := range params.CategoryIDs {
+		if key, value, found := strings.Cut(category, "="); found {
+			tagFilters = append(tagFilters, "["+quote(key)+"="+quote(value)+"]")
+		}
+	}
+
+	var query strings.Builder
+	query.WriteString("[out:json][timeout:25];(")
+	for _, tagFilter := range tagFilters {
+		fmt.Fprintf(&query, `nw%s%s(%s);`, nameFilter, tagFilter, around)
+	}
+	fmt.Fprintf(&query, ");out center %d;", limit)
+
+	resp, err := rest.GetJSON[response](ctx, c.rest, c.baseURL+"/interpreter", rest.RequestData{
+		Query: url.Values{
+			"data": {query.String()},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	venues := make([]domain.Venue, 0, len(resp.Elements))
+	for _, e := range resp.Elements {
+		venues = append(venues, e.toVenue())
+	}
+	return venues, nil
+}
+
+// GetVenue implements the venue.Provider interface,
+// the categories are the tags of the element with one of the configured keys.
+func (c Client) GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+	elementType, rawID, _ := strings.Cut(venueID, "-")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || (elementType != "node" && elementType != "way") {
+		return domain.VenueDetails{}, domain.BadRequestErr("invalid-openstreetmap-venue-id", map[string]interface{}{
+			"venue_id": venueID,
+		})
+	}
+
+	resp, err := rest.GetJSON[response](ctx, c.rest, c.baseURL+"/interpreter", rest.RequestData{
+		Query: url.Values{
+			"data": {fmt.Sprintf("[out:json][timeout:25];%s(%d);out center;", elementType, id)},
+		},
+	})
+	if err != nil {
+		return domain.VenueDetails{}, err
+	}
+	if len(resp.Elements) == 0 {
+		return domain.VenueDetails{}, domain.NotFoundErr("venue-not-found", map[string]interface{}{
+			"venue_id": venueID,
+		})
+	}
+	e := resp.Elements[0]
+
+	venue := e.toVenue()
+	details := domain.VenueDetails{
+		ID:          venue.ID,
+		Name:        venue.Name,
+		Description: e.Tags["description"],
+		Location:    venue.Location,
+		Hours: domain.OpeningHours{
+			// Parsing the opening_hours syntax is out of the scope of this adapter:
+			Display: e.Tags["opening_hours"],
+		},
+		Contact: domain.Contact{
+			Phone:   e.tag("phone", "contact:phone"),
+			Website: e.tag("website", "contact:website"),
+			Email:   e.tag("email", "contact:email"),
+			Twitter: e.Tags["contact:twitter"],
+		},
+	}
+
+	for _, key := range c.config.Keys {
+		if value, ok := e.Tags[key]; ok {
+			details.Categories = append(details.Categories, domain.Category{
+				ID:   key + "=" + value,
+				Name: value,
+			})
+		}
+	}
+
+	return details, nil
+}
+
+// tag returns the value of the first of the keys present on the tags
+func (e element) tag(keys ...string) string {
+	for _, key := range keys {
+		if value, ok := e.Tags[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func (e element) toVenue() domain.Venue {
+	lat, lng := e.Lat, e.Lon
+	if e.Center != nil {
+		lat, lng = e.Center.Lat, e.Center.Lon
+	}
+
+	address := strings.TrimSpace(e.Tags["addr:housenumber"] + " " + e.Tags["addr:street"])
+
+	var formattedAddress []string
+	if address != "" {
+		formattedAddress = append(formattedAddress, address)
+	}
+	if cityLine := strings.TrimSpace(e.Tags["addr:city"] + " " + e.Tags["addr:postcode"]); cityLine != "" {
+		formattedAddress = append(formattedAddress, cityLine)
+	}
+
+	return domain.Venue{
+		ID:   e.Type + "-" + strconv.FormatInt(e.ID, 10),
+		Name: e.Tags["name"],
+		Location: domain.Location{
+			Address:          address,
+			Latitude:         lat,
+			Longitude:        lng,
+			PostalCode:       e.Tags["addr:postcode"],
+			CountryCode:      e.Tags["addr:country"],
+			City:             e.Tags["addr:city"],
+			State:            e.Tags["addr:state"],
+			FormattedAddress: formattedAddress,
+		},
+	}
+}
+
+// quote builds a string literal of the Overpass query language
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
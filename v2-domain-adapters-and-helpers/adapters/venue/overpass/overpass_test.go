@@ -0,0 +1,153 @@
+package overpass
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	resthttp "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+// newFixtureServer serves the files on testdata/ the same way the Overpass API would
+func newFixtureServer(t *testing.T, receivedQueries *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("data")
+		*receivedQueries = append(*receivedQueries, query)
+
+		file := "testdata/search.json"
+		switch {
+		case strings.Contains(query, "node(2709306673)"):
+			file = "testdata/details.json"
+		case strings.Contains(query, "node(1)"):
+			w.Write([]byte(`{"elements":[]}`))
+			return
+		}
+
+		body, err := os.ReadFile(file)
+		tt.AssertNoErr(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetVenues(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should map the search params into the query", func(t *testing.T) {
+		var queries []string
+		server := newFixtureServer(t, &queries)
+
+		client := New(resthttp.New(time.Second), server.URL, Config{
+			Keys: []string{"amenity", "shop"},
+		})
+
+		_, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      500,
+			Query:       `Joe's "Pizza"`,
+			CategoryIDs: []string{"restaurant", "tourism=museum"},
+			Limit:       10,
+		})
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, queries, []string{
+			`[out:json][timeout:25];(` +
+				`nw["name"~"Joe's \"Pizza\"",i]["amenity"="restaurant"](around:500,40.7484,-73.9857);` +
+				`nw["name"~"Joe's \"Pizza\"",i]["shop"="restaurant"](around:500,40.7484,-73.9857);` +
+				`nw["name"~"Joe's \"Pizza\"",i]["tourism"="museum"](around:500,40.7484,-73.9857);` +
+				`);out center 10;`,
+		})
+	})
+
+	t.Run("should convert the elements into venues", func(t *testing.T) {
+		var queries []string
+		server := newFixtureServer(t, &queries)
+
+		client := New(resthttp.New(time.Second), server.URL, Config{
+			Radius: 100,
+			Keys:   []string{"amenity"},
+		})
+
+		venues, err := client.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+
+		tt.AssertEqual(t, queries, []string{
+			`[out:json][timeout:25];(nw["name"]["amenity"](around:100,40.7484,-73.9857););out center 50;`,
+		})
+
+		tt.AssertEqual(t, venues, []domain.Venue{
+			{
+				ID:   "way-34633854",
+				Name: "Empire State Building",
+				Location: domain.Location{
+					Address:          "350 5th Avenue",
+					Latitude:         40.7484284,
+					Longitude:        -73.9856546,
+					PostalCode:       "10118",
+					City:             "New York",
+					State:            "NY",
+					FormattedAddress: []string{"350 5th Avenue", "New York 10118"},
+				},
+			},
+			{
+				ID:   "node-2709306673",
+				Name: "Starbucks",
+				Location: domain.Location{
+					Latitude:  40.7481534,
+					Longitude: -73.9862431,
+				},
+			},
+		})
+	})
+}
+
+func TestGetVenue(t *testing.T) {
+	ctx := context.Background()
+
+	var queries []string
+	server := newFixtureServer(t, &queries)
+	client := New(resthttp.New(time.Second), server.URL, Config{})
+
+	t.Run("should return the element of the venue", func(t *testing.T) {
+		venue, err := client.GetVenue(ctx, "node-2709306673")
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, venue, domain.VenueDetails{
+			ID:   "node-2709306673",
+			Name: "Starbucks",
+			Location: domain.Location{
+				Latitude:  40.7481534,
+				Longitude: -73.9862431,
+			},
+			Categories: []domain.Category{
+				{ID: "amenity=cafe", Name: "cafe"},
+			},
+			Hours: domain.OpeningHours{
+				Display: "Mo-Fr 06:00-20:00; Sa-Su 07:00-19:00",
+			},
+			Contact: domain.Contact{
+				Phone:   "+1 212-555-0100",
+				Website: "https://www.starbucks.com",
+			},
+		})
+	})
+
+	t.Run("should return NotFoundErr for missing elements", func(t *testing.T) {
+		_, err := client.GetVenue(ctx, "node-1")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "NotFoundErr")
+	})
+
+	t.Run("should reject invalid IDs", func(t *testing.T) {
+		_, err := client.GetVenue(ctx, "43695300f964a5208c291fe3")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "BadRequestErr")
+	})
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -22,7 +25,17 @@ import (
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache/redis"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log/jsonlogs"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/metrics/memorymetrics"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/circuitbreaker"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/httpcache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/ratelimit"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/restlogs"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue/foursquare"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue/foursquarev3"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue/overpass"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/env"
 
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/repo"
@@ -36,10 +49,40 @@ func main() {
 
 	// Read all configs at once so its easy to spot all of them:
 	port := env.GetString("PORT", "80")
+	adminPort := env.GetString("ADMIN_PORT", "9090")
 	logLevel := env.GetString("LOG_LEVEL", "INFO")
-	foursquareBaseURL := env.MustGetString("FOURSQUARE_BASE_URL")
-	foursquareClientID := env.MustGetString("FOURSQUARE_CLIENT_ID")
-	foursquareSecret := env.MustGetString("FOURSQUARE_SECRET")
+	venueProvider := env.GetString("VENUE_PROVIDER", "foursquare")
+	overpassBaseURL := env.GetString("OVERPASS_BASE_URL", "https://overpass-api.de/api")
+	overpassTransport := http.TransportConfig{
+		ProxyURL:            env.GetString("OVERPASS_PROXY_URL", ""),
+		NoProxy:             env.GetString("OVERPASS_NO_PROXY", ""),
+		CABundleFile:        env.GetString("OVERPASS_CA_BUNDLE", ""),
+		ClientCertFile:      env.GetString("OVERPASS_CLIENT_CERT", ""),
+		ClientKeyFile:       env.GetString("OVERPASS_CLIENT_KEY", ""),
+		MaxIdleConnsPerHost: env.GetInt("OVERPASS_MAX_IDLE_CONNS_PER_HOST", 10),
+		IdleConnTimeout:     env.GetDuration("OVERPASS_IDLE_CONN_TIMEOUT", 90*time.Second),
+		DialTimeout:         env.GetDuration("OVERPASS_DIAL_TIMEOUT", 5*time.Second),
+		TLSHandshakeTimeout: env.GetDuration("OVERPASS_TLS_HANDSHAKE_TIMEOUT", 5*time.Second),
+	}
+	searchCachePrecision := env.GetInt("VENUE_SEARCH_CACHE_PRECISION", 0)
+	foursquareBaseURL := env.GetString("FOURSQUARE_BASE_URL", "https://api.foursquare.com/v2")
+	foursquareClientID := env.GetString("FOURSQUARE_CLIENT_ID", "")
+	foursquareSecret := env.GetString("FOURSQUARE_SECRET", "")
+	foursquareV3BaseURL := env.GetString("FOURSQUARE_V3_BASE_URL", "https://api.foursquare.com/v3")
+	foursquareAPIKey := env.GetString("FOURSQUARE_API_KEY", "")
+	foursquareRateLimit := env.GetFloat("FOURSQUARE_RATE_LIMIT", 0)
+	foursquareRateBurst := env.GetInt("FOURSQUARE_RATE_BURST", 1)
+	foursquareTransport := http.TransportConfig{
+		ProxyURL:            env.GetString("FOURSQUARE_PROXY_URL", ""),
+		NoProxy:             env.GetString("FOURSQUARE_NO_PROXY", ""),
+		CABundleFile:        env.GetString("FOURSQUARE_CA_BUNDLE", ""),
+		ClientCertFile:      env.GetString("FOURSQUARE_CLIENT_CERT", ""),
+		ClientKeyFile:       env.GetString("FOURSQUARE_CLIENT_KEY", ""),
+		MaxIdleConnsPerHost: env.GetInt("FOURSQUARE_MAX_IDLE_CONNS_PER_HOST", 10),
+		IdleConnTimeout:     env.GetDuration("FOURSQUARE_IDLE_CONN_TIMEOUT", 90*time.Second),
+		DialTimeout:         env.GetDuration("FOURSQUARE_DIAL_TIMEOUT", 5*time.Second),
+		TLSHandshakeTimeout: env.GetDuration("FOURSQUARE_TLS_HANDSHAKE_TIMEOUT", 5*time.Second),
+	}
 	redisURL := env.GetString("REDIS_URL", "")
 	redisPassword := env.GetString("REDIS_PASSWORD", "")
 	dbURL := env.MustGetString("DATABASE_URL")
@@ -49,13 +92,23 @@ func main() {
 
 	err := startAPI(ctx,
 		logger,
+		venueProvider,
+		overpassBaseURL,
+		overpassTransport,
+		searchCachePrecision,
 		foursquareBaseURL,
 		foursquareClientID,
 		foursquareSecret,
+		foursquareV3BaseURL,
+		foursquareAPIKey,
+		foursquareRateLimit,
+		foursquareRateBurst,
+		foursquareTransport,
 		redisURL,
 		redisPassword,
 		dbURL,
 		port,
+		adminPort,
 	)
 	if err != nil {
 		logger.Error(ctx, "server-stopped-with-an-error", log.Body{
@@ -67,34 +120,109 @@ func main() {
 func startAPI(
 	ctx context.Context,
 	logger log.Provider,
+	venueProvider string,
+	overpassBaseURL string,
+	overpassTransport http.TransportConfig,
+	searchCachePrecision int,
 	foursquareBaseURL string,
 	foursquareClientID string,
 	foursquareSecret string,
+	foursquareV3BaseURL string,
+	foursquareAPIKey string,
+	foursquareRateLimit float64,
+	foursquareRateBurst int,
+	foursquareTransport http.TransportConfig,
 	redisURL string,
 	redisPassword string,
 	dbURL string,
 	port string,
+	adminPort string,
 ) error {
-	restClient := http.New(30 * time.Second)
+	if searchCachePrecision != 0 && (searchCachePrecision < venues.MinSearchCachePrecision || searchCachePrecision > venues.MaxSearchCachePrecision) {
+		return fmt.Errorf(
+			"VENUE_SEARCH_CACHE_PRECISION must be 0 or between %d and %d, got %d",
+			venues.MinSearchCachePrecision, venues.MaxSearchCachePrecision, searchCachePrecision,
+		)
+	}
 
 	var cacheClient cache.Provider
+	var rateLimiter ratelimit.Limiter
 	if redisURL != "" {
-		cacheClient = redis.New(redisURL, redisPassword, 24*time.Hour)
+		redisClient := redis.New(redisURL, redisPassword, 24*time.Hour)
+		cacheClient = redisClient
+		// Using redis for the rate limits allows all instances of the API to share them:
+		rateLimiter = redisClient
 	} else {
 		cacheClient = memorycache.New(24*time.Hour, 10*time.Minute)
+		rateLimiter = ratelimit.NewMemoryLimiter()
 	}
 
-	venuesService := venues.NewService(
-		logger,
-		restClient,
-		cacheClient,
-		foursquareBaseURL,
-		foursquareClientID,
-		foursquareSecret,
-	)
+	rateLimitsByHost := map[string]ratelimit.Limit{}
+	for _, baseURL := range []string{foursquareBaseURL, foursquareV3BaseURL} {
+		if u, err := url.Parse(baseURL); err == nil && foursquareRateLimit > 0 {
+			rateLimitsByHost[u.Host] = ratelimit.Limit{
+				Rate:  foursquareRateLimit,
+				Burst: foursquareRateBurst,
+			}
+		}
+	}
+
+	metricsClient := memorymetrics.New(map[string][]float64{
+		restlogs.SizeMetric: restlogs.SizeBuckets,
+	})
+
+	// Each upstream has its own transport, so the proxy and TLS settings
+	// of one upstream are never used for reaching the others:
+	foursquareClient, err := newRestClient(logger, foursquareTransport, rateLimitsByHost, rateLimiter, cacheClient, metricsClient)
+	if err != nil {
+		return fmt.Errorf("unable to configure the foursquare transport: %w", err)
+	}
+
+	overpassClient, err := newRestClient(logger, overpassTransport, nil, rateLimiter, cacheClient, metricsClient)
+	if err != nil {
+		return fmt.Errorf("unable to configure the overpass transport: %w", err)
+	}
+
+	// Several providers can be listed separated by commas, e.g. "foursquare,overpass",
+	// the first one is the main provider and the IDs of the venues found by the others
+	// are prefixed with their names, so their details are fetched from the same provider:
+	var venueProviders []venues.NamedProvider
+	for _, name := range strings.Split(venueProvider, ",") {
+		name = strings.TrimSpace(name)
+
+		var provider venue.Provider
+		switch name {
+		case "foursquare":
+			if foursquareClientID == "" || foursquareSecret == "" {
+				return fmt.Errorf("FOURSQUARE_CLIENT_ID and FOURSQUARE_SECRET are required when VENUE_PROVIDER includes foursquare")
+			}
+			provider = foursquare.New(foursquareClient, foursquareBaseURL, foursquareClientID, foursquareSecret)
+		case "foursquare-v3":
+			if foursquareAPIKey == "" {
+				return fmt.Errorf("FOURSQUARE_API_KEY is required when VENUE_PROVIDER includes foursquare-v3")
+			}
+			provider = foursquarev3.New(foursquareClient, foursquareV3BaseURL, foursquareAPIKey, foursquarev3.Config{})
+		case "overpass":
+			provider = overpass.New(overpassClient, overpassBaseURL, overpass.Config{})
+		default:
+			return fmt.Errorf("unknown VENUE_PROVIDER '%s', expected 'foursquare', 'foursquare-v3' or 'overpass'", name)
+		}
+
+		venueProviders = append(venueProviders, venues.NamedProvider{
+			Name:     name,
+			Provider: provider,
+		})
+	}
+
+	venuesService := venues.NewService(logger, venueProviders[0].Provider, cacheClient, venues.Config{
+		ProviderName:   venueProviders[0].Name,
+		ExtraProviders: venueProviders[1:],
+
+		SearchCachePrecision: searchCachePrecision,
+	})
 
 	var repo repo.Provider
-	repo, err := pgrepo.New(ctx, dbURL)
+	repo, err = pgrepo.New(ctx, dbURL)
 	if err != nil {
 		logger.Fatal(ctx, "unable to start database", log.Body{
 			"db_url": dbURL,
@@ -131,6 +259,8 @@ func startAPI(
 	app.Post("/users", usersController.UpsertUser)
 	app.Get("/users/:id", usersController.GetUser)
 
+	app.Get("/venues/area", venuesController.GetVenuesInArea)
+	app.Post("/venues/area", venuesController.SearchVenuesInArea)
 	app.Get("/venues/:latitude,:longitude", venuesController.GetVenuesByCoordinates)
 	app.Get("/venues/details/:id", venuesController.GetDetails)
 
@@ -141,8 +271,17 @@ func startAPI(
 		return assets.WriteExamplePage(c, "username", "user address", 42)
 	})
 
+	// The metrics are served on a separate port so they are never
+	// exposed together with the public endpoints:
+	adminApp := fiber.New()
+	adminApp.Get("/metrics", func(c fiber.Ctx) error {
+		c.Set("Content-Type", "text/plain; version=0.0.4")
+		return metricsClient.WriteText(c)
+	})
+
 	logger.Info(ctx, "server-starting-up", log.Body{
-		"port": port,
+		"port":       port,
+		"admin_port": adminPort,
 	})
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -154,5 +293,81 @@ func startAPI(
 		return app.Shutdown()
 	})
 
+	// An empty admin port disables the admin endpoints:
+	if adminPort != "" {
+		g.Go(func() error {
+			return adminApp.Listen(":" + adminPort)
+		})
+		g.Go(func() error {
+			<-ctx.Done()
+			return adminApp.Shutdown()
+		})
+	}
+
 	return g.Wait()
 }
+
+// newRestClient builds the rest client used for reaching one upstream,
+// decorating an http client that uses its own transport.
+func newRestClient(
+	logger log.Provider,
+	transportConfig http.TransportConfig,
+	rateLimitsByHost map[string]ratelimit.Limit,
+	rateLimiter ratelimit.Limiter,
+	cacheClient cache.Provider,
+	metricsClient *memorymetrics.Client,
+) (rest.Provider, error) {
+	transport, err := http.NewTransport(transportConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rate limiter keeps us under the quotas of the upstream APIs,
+	// it decorates the transport so each retry and hedged request is counted:
+	rateLimitedTransport := ratelimit.NewTransport(transport, ratelimit.Config{
+		LimitsByHost: rateLimitsByHost,
+		MaxWait:      2 * time.Second,
+		Limiter:      rateLimiter,
+	})
+
+	httpClient := http.NewWithConfig(http.Config{
+		Timeout:   30 * time.Second,
+		Transport: rateLimitedTransport,
+		Retry: http.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 200 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+		},
+		Logger: logger,
+
+		// Protects us from loading huge payloads into memory:
+		MaxResponseSize: 10 * 1024 * 1024,
+
+		// Forwarding the request ID allows us to correlate our logs with upstream logs:
+		Propagation: http.Propagation{
+			RequestIDHeader: "request-id",
+			TraceParent:     true,
+		},
+	})
+
+	// Logging right above the http client reports each call once, so the
+	// durations include the retries, backoffs and hedged requests, the
+	// individual attempts are only logged by the http client on failures.
+	// Requests served by the cache are not reported:
+	loggedClient := restlogs.New(httpClient, restlogs.Config{
+		Logger:  logger,
+		Metrics: metricsClient,
+	})
+
+	// The circuit breaker makes requests fail fast when an upstream host is down
+	// instead of making every request wait for the timeout:
+	breakerClient := circuitbreaker.New(loggedClient, circuitbreaker.Config{
+		Logger: logger,
+	})
+
+	// The http cache is the outermost decorator so cached responses
+	// don't count towards the rate limits:
+	return httpcache.New(breakerClient, cacheClient, httpcache.Config{
+		Logger: logger,
+	}), nil
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log"
 	"net"
-	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
@@ -16,7 +15,9 @@ import (
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log/jsonlogs"
+	resthttp "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest/http"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/fakefoursquare"
 	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
 	migrations "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/migrations"
 	"github.com/vingarcia/krest"
@@ -26,10 +27,10 @@ import (
 )
 
 type testData struct {
-	db          ksql.Provider
-	serverURL   string
-	http        krest.Provider
-	mockHandler *http.HandlerFunc
+	db         ksql.Provider
+	serverURL  string
+	http       krest.Provider
+	foursquare *fakefoursquare.Server
 }
 
 func TestAPI(t *testing.T) {
@@ -51,12 +52,15 @@ func TestAPI(t *testing.T) {
 
 	restCli := krest.New(30 * time.Second)
 
-	var mockHandler http.HandlerFunc
-
-	// This fake server will replace any external server we depend on
-	fakeServer := httptest.NewServer(&mockHandler)
+	// This fake server replaces the real Foursquare API:
+	foursquare := fakefoursquare.New(fakefoursquare.Config{
+		ClientID:     "fakeFoursquareClientID",
+		ClientSecret: "fakeFoursquareSecret",
+	})
+	fakeServer := httptest.NewServer(foursquare)
+	defer fakeServer.Close()
 
-	foursquareBaseURL := fakeServer.URL
+	foursquareBaseURL := fakeServer.URL + "/v2"
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -65,12 +69,19 @@ func TestAPI(t *testing.T) {
 		err := startAPI(
 			ctx,
 			jsonlogs.New("INFO", domain.GetCtxValues),
+			"foursquare", "", // Overpass is not used on the tests
+			resthttp.TransportConfig{},
+			0, // No search caching so each test reaches the fake server
 			foursquareBaseURL,
 			"fakeFoursquareClientID",
 			"fakeFoursquareSecret",
+			"", "", // The Places API (v3) is not used on the tests
+			0, 1, // No rate limits on the tests
+			resthttp.TransportConfig{},
 			"", "", // Not using redis so we keep it with empty strings
 			dbURL,
 			port,
+			"", // No admin endpoints on the tests
 		)
 		tt.AssertNoErr(t, err)
 		return nil
@@ -79,14 +90,15 @@ func TestAPI(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 
 	testData := testData{
-		db:          db,
-		serverURL:   "http://localhost:" + port,
-		http:        restCli,
-		mockHandler: &mockHandler,
+		db:         db,
+		serverURL:  "http://localhost:" + port,
+		http:       restCli,
+		foursquare: foursquare,
 	}
 
 	healthCheckTest(ctx, t, testData)
 	usersTest(ctx, t, testData)
+	venuesTest(ctx, t, testData)
 
 	cancel()
 	g.Wait()
@@ -186,10 +198,7 @@ func startPostgresDB(ctx context.Context, dbName string) (databaseURL string, cl
 }
 
 func resetTestState(ctx context.Context, data testData) {
-	*data.mockHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(400)
-		w.Write([]byte("mockHandler: unexpected request to: " + r.URL.Path))
-	})
+	data.foursquare.Reset()
 
 	data.db.Exec(ctx, "DELETE FROM users")
 }
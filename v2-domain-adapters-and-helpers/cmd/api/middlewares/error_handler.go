@@ -63,7 +63,7 @@ func handleDomainErrAsHTTP(ctx context.Context, logger log.Provider, err error,
 		}
 		logger.Error(ctx, "request-error", data)
 
-	case "BadRequest":
+	case "BadRequestErr":
 		status = 400
 		for k, v := range domainErr.Data {
 			response[k] = v
@@ -74,6 +74,18 @@ func handleDomainErrAsHTTP(ctx context.Context, logger log.Provider, err error,
 		for k, v := range domainErr.Data {
 			response[k] = v
 		}
+
+	case "UnavailableErr":
+		status = 503
+		for k, v := range domainErr.Data {
+			response[k] = v
+		}
+
+	case "RateLimitedErr":
+		status = 429
+		for k, v := range domainErr.Data {
+			response[k] = v
+		}
 	}
 
 	responseBody, _ = json.Marshal(response)
@@ -8,6 +8,9 @@ import (
 // HandleRequestID will try to read a `request-id`
 // key from the request headers and if it is not available
 // generate a random one and put it in the request context.
+//
+// If the request contains a W3C `traceparent` header it is
+// also saved on the context so it can be forwarded upstream.
 func HandleRequestID() func(c fiber.Ctx) error {
 	return func(c fiber.Ctx) error {
 		requestID := c.Get("request-id")
@@ -16,6 +19,12 @@ func HandleRequestID() func(c fiber.Ctx) error {
 		}
 
 		c.Locals(domain.RequestIDKey, requestID)
+
+		traceParent := c.Get("traceparent")
+		if traceParent != "" {
+			c.Locals(domain.TraceParentKey, traceParent)
+		}
+
 		return c.Next()
 	}
 }
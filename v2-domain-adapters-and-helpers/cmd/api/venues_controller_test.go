@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+	"github.com/vingarcia/krest"
+)
+
+func venuesTest(ctx context.Context, t *testing.T, data testData) {
+	// The Foursquare API is replaced by the fake server
+	// from the helpers/fakefoursquare package.
+	t.Run("GET /venues/:latitude,:longitude", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			path               string
+			query              string
+			expectedIDs        []string
+			expectErrToContain []string
+		}{
+			{
+				desc:        "should return the closest venues",
+				path:        "/venues/40.7484,-73.9857",
+				query:       "?radius=1000&limit=2",
+				expectedIDs: []string{"43695300f964a5208c291fe3", "4b7efa2ef964a520580130e3"},
+			},
+			{
+				desc:               "should reject invalid coordinates",
+				path:               "/venues/91,-73.9857",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-coordinates", "must be between -90 and 90"},
+			},
+			{
+				desc:               "should reject search params that are not numbers",
+				query:              "?radius=abc",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-query-param", "radius"},
+			},
+			{
+				desc:               "should reject search params out of range",
+				query:              "?limit=100",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-search-limit"},
+			},
+			{
+				desc:               "should reject invalid categories",
+				query:              "?categories=13065,not%20valid",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-search-category"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				resetTestState(ctx, data)
+
+				path := test.path
+				if path == "" {
+					path = "/venues/40.7484,-73.9857"
+				}
+
+				resp, err := data.http.Get(ctx, data.serverURL+path+test.query, krest.RequestData{})
+				if test.expectErrToContain != nil {
+					tt.AssertErrContains(t, err, test.expectErrToContain...)
+					return
+				}
+				tt.AssertNoErr(t, err)
+
+				// With a single provider the response is a list of venues:
+				tt.AssertEqual(t, parseVenueListIDs(t, resp.Body), test.expectedIDs)
+			})
+		}
+	})
+
+	t.Run("GET /venues/area", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			query              string
+			expectedIDs        []string
+			expectErrToContain []string
+		}{
+			{
+				desc:  "should return the venues inside the area closest to its center",
+				query: "?bbox=-73.995,40.74,-73.975,40.76&limit=3",
+				expectedIDs: []string{
+					"4b7efa2ef964a520580130e3",
+					"43695300f964a5208c291fe3",
+					"4a2d5cf7f964a520d8971fe3",
+				},
+			},
+			{
+				desc:               "should reject invalid bounding boxes",
+				query:              "?bbox=-73.995,40.76,-73.975,40.74",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-bounding-box"},
+			},
+			{
+				desc:               "should reject areas that are too large",
+				query:              "?bbox=-74.3,40.5,-73.7,40.9",
+				expectErrToContain: []string{"400", "BadRequestErr", "search-area-too-large"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				resetTestState(ctx, data)
+
+				resp, err := data.http.Get(ctx, data.serverURL+"/venues/area"+test.query, krest.RequestData{})
+				if test.expectErrToContain != nil {
+					tt.AssertErrContains(t, err, test.expectErrToContain...)
+					return
+				}
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, parseVenueIDs(t, resp.Body), test.expectedIDs)
+			})
+		}
+	})
+
+	t.Run("POST /venues/area", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			requestBody        map[string]any
+			expectedIDs        []string
+			expectErrToContain []string
+		}{
+			{
+				desc: "should return the venues inside the polygon",
+				requestBody: map[string]any{
+					"polygon": map[string]any{
+						"type": "Polygon",
+						"coordinates": [][][]float64{{
+							{-73.99, 40.745}, {-73.98, 40.745}, {-73.98, 40.752}, {-73.99, 40.752}, {-73.99, 40.745},
+						}},
+					},
+				},
+				expectedIDs: []string{
+					"4b7efa2ef964a520580130e3",
+					"43695300f964a5208c291fe3",
+					"4a2d5cf7f964a520d8971fe3",
+				},
+			},
+			{
+				desc: "should reject geometries other than polygons",
+				requestBody: map[string]any{
+					"polygon": map[string]any{
+						"type":        "Point",
+						"coordinates": []float64{-73.99, 40.745},
+					},
+				},
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-polygon"},
+			},
+			{
+				desc:               "should require an area",
+				requestBody:        map[string]any{"query": "park"},
+				expectErrToContain: []string{"400", "BadRequestErr", "missing-search-area"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				resetTestState(ctx, data)
+
+				resp, err := data.http.Post(ctx, data.serverURL+"/venues/area", krest.RequestData{
+					Body: test.requestBody,
+				})
+				if test.expectErrToContain != nil {
+					tt.AssertErrContains(t, err, test.expectErrToContain...)
+					return
+				}
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, parseVenueIDs(t, resp.Body), test.expectedIDs)
+			})
+		}
+	})
+
+	t.Run("GET /venues/details/:id", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			venueID            string
+			upstreamFailures   int
+			expectedName       string
+			expectErrToContain []string
+
+			// The details of the requests to foursquare should only be logged:
+			expectErrNotToContain []string
+		}{
+			{
+				desc:         "should retrieve the details of a venue",
+				venueID:      "43695300f964a5208c291fe3",
+				expectedName: "Empire State Building",
+			},
+			{
+				desc:             "should retry when foursquare fails temporarily",
+				venueID:          "4a2d5cf7f964a520d8971fe3",
+				upstreamFailures: 1,
+				expectedName:     "Macy's Herald Square",
+			},
+			{
+				desc:               "should return 503 when foursquare is unavailable",
+				venueID:            "40b13b00f964a5209bf61ee3",
+				upstreamFailures:   3,
+				expectErrToContain: []string{"503", "UnavailableErr", "venue_id"},
+				expectErrNotToContain: []string{
+					`"url"`, `"payload"`, `"method"`, `"status_code"`, "/v2/venues",
+				},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				resetTestState(ctx, data)
+				data.foursquare.FailNext(test.upstreamFailures, 503)
+
+				resp, err := data.http.Get(ctx, data.serverURL+"/venues/details/"+test.venueID, krest.RequestData{})
+				if test.expectErrToContain != nil {
+					tt.AssertErrContains(t, err, test.expectErrToContain...)
+					for _, substr := range test.expectErrNotToContain {
+						tt.AssertFalse(t, strings.Contains(err.Error(), substr))
+					}
+					return
+				}
+				tt.AssertNoErr(t, err)
+
+				var body struct {
+					ID         string `json:"id"`
+					Name       string `json:"name"`
+					Categories []struct {
+						Name string `json:"name"`
+					} `json:"categories"`
+					Location struct {
+						Latitude float64 `json:"latitude"`
+					} `json:"location"`
+				}
+				err = json.Unmarshal(resp.Body, &body)
+				tt.AssertNoErr(t, err)
+
+				tt.AssertEqual(t, body.ID, test.venueID)
+				tt.AssertEqual(t, body.Name, test.expectedName)
+				tt.AssertEqual(t, len(body.Categories), 1)
+				tt.AssertTrue(t, body.Location.Latitude != 0)
+			})
+		}
+	})
+
+	t.Run("GeoJSON output", func(t *testing.T) {
+		tests := []struct {
+			desc               string
+			path               string
+			headers            map[string]any
+			expectedIDs        []string
+			expectErrToContain []string
+		}{
+			{
+				desc:        "should return the search as GeoJSON with the format param",
+				path:        "/venues/area?bbox=-73.995,40.74,-73.975,40.76&limit=2&format=geojson",
+				expectedIDs: []string{"4b7efa2ef964a520580130e3", "43695300f964a5208c291fe3"},
+			},
+			{
+				desc:        "should return the details as GeoJSON with the Accept header",
+				path:        "/venues/details/43695300f964a5208c291fe3",
+				headers:     map[string]any{"Accept": "application/geo+json"},
+				expectedIDs: []string{"43695300f964a5208c291fe3"},
+			},
+			{
+				desc:               "should reject unknown formats",
+				path:               "/venues/details/43695300f964a5208c291fe3?format=xml",
+				expectErrToContain: []string{"400", "BadRequestErr", "invalid-query-param", "format"},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.desc, func(t *testing.T) {
+				resetTestState(ctx, data)
+
+				resp, err := data.http.Get(ctx, data.serverURL+test.path, krest.RequestData{
+					Headers: test.headers,
+				})
+				if test.expectErrToContain != nil {
+					tt.AssertErrContains(t, err, test.expectErrToContain...)
+					return
+				}
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, resp.Header.Get("Content-Type"), "application/geo+json")
+
+				var body struct {
+					Type     string `json:"type"`
+					Features []struct {
+						Type     string `json:"type"`
+						ID       string `json:"id"`
+						Geometry struct {
+							Type        string     `json:"type"`
+							Coordinates [2]float64 `json:"coordinates"`
+						} `json:"geometry"`
+						Properties struct {
+							Name string `json:"name"`
+						} `json:"properties"`
+					} `json:"features"`
+				}
+				err = json.Unmarshal(resp.Body, &body)
+				tt.AssertNoErr(t, err)
+
+				tt.AssertEqual(t, body.Type, "FeatureCollection")
+				var ids []string
+				for _, f := range body.Features {
+					tt.AssertEqual(t, f.Type, "Feature")
+					tt.AssertEqual(t, f.Geometry.Type, "Point")
+					// GeoJSON positions are <longitude>, <latitude>:
+					tt.AssertTrue(t, f.Geometry.Coordinates[0] < -73 && f.Geometry.Coordinates[1] > 40)
+					tt.AssertTrue(t, f.Properties.Name != "")
+					ids = append(ids, f.ID)
+				}
+				tt.AssertEqual(t, ids, test.expectedIDs)
+			})
+		}
+	})
+}
+
+func parseVenueIDs(t *testing.T, rawJSON []byte) []string {
+	var body struct {
+		Venues []struct {
+			ID string
+		} `json:"venues"`
+	}
+	err := json.Unmarshal(rawJSON, &body)
+	tt.AssertNoErr(t, err)
+
+	var ids []string
+	for _, v := range body.Venues {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func parseVenueListIDs(t *testing.T, rawJSON []byte) []string {
+	var venues []struct {
+		ID string
+	}
+	err := json.Unmarshal(rawJSON, &venues)
+	tt.AssertNoErr(t, err)
+
+	var ids []string
+	for _, v := range venues {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
@@ -0,0 +1,186 @@
+package venuesctrl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+const geoJSONContentType = "application/geo+json"
+
+// wantsGeoJSON checks if the client asked for GeoJSON, either with
+// `?format=geojson` or with the `Accept: application/geo+json` header,
+// the query param takes precedence since it is easier to use from a browser.
+func wantsGeoJSON(ctx fiber.Ctx) (bool, error) {
+	switch ctx.Query("format") {
+	case "geojson":
+		return true, nil
+	case "json":
+		return false, nil
+	case "":
+	default:
+		return false, domain.BadRequestErr("invalid-query-param", map[string]interface{}{
+			"param":    "format",
+			"received": ctx.Query("format"),
+			"expected": "json or geojson",
+		})
+	}
+
+	ctx.Vary(fiber.HeaderAccept)
+	return ctx.Accepts(fiber.MIMEApplicationJSON, geoJSONContentType) == geoJSONContentType, nil
+}
+
+// The types below follow RFC 7946, the positions are in the <longitude>, <latitude> order:
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+
+	// Foreign members are allowed by the RFC,
+	// so the warnings of the search are kept:
+	Warnings []domain.SearchWarning `json:"warnings,omitempty"`
+}
+
+type feature struct {
+	Type       string      `json:"type"`
+	ID         string      `json:"id"`
+	Geometry   point       `json:"geometry"`
+	Properties interface{} `json:"properties"`
+}
+
+type point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type venueProperties struct {
+	Name     string           `json:"name"`
+	Location locationResponse `json:"location"`
+	Distance int              `json:"distance"`
+	Bearing  float64          `json:"bearing"`
+}
+
+// geoJSONGeometry is the polygon received on the area searches
+type geoJSONGeometry struct {
+	Type string `json:"type"`
+
+	// The format of the coordinates depends on the type
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// toPolygon converts the GeoJSON positions, which are in the
+// <longitude>, <latitude> order, into a domain.Polygon
+func (g geoJSONGeometry) toPolygon() (domain.Polygon, error) {
+	if g.Type != "Polygon" {
+		return nil, domain.BadRequestErr("invalid-polygon", map[string]interface{}{
+			"received_type": g.Type,
+			"reason":        "only geometries of type Polygon are supported",
+		})
+	}
+
+	var rings [][][]float64
+	err := json.Unmarshal(g.Coordinates, &rings)
+	if err != nil {
+		return nil, domain.BadRequestErr("invalid-polygon", map[string]interface{}{
+			"reason": "the coordinates must be a list of rings of [<longitude>, <latitude>] positions",
+			"error":  err.Error(),
+		})
+	}
+
+	polygon := domain.Polygon{}
+	for _, ring := range rings {
+		var coordinates []domain.Coordinates
+		for _, position := range ring {
+			if len(position) < 2 {
+				return nil, domain.BadRequestErr("invalid-polygon", map[string]interface{}{
+					"received_position": position,
+					"reason":            "the positions must be [<longitude>, <latitude>]",
+				})
+			}
+			coordinates = append(coordinates, domain.Coordinates{
+				Latitude:  position[1],
+				Longitude: position[0],
+			})
+		}
+		polygon = append(polygon, coordinates)
+	}
+	return polygon, nil
+}
+
+func newFeature(id string, location domain.Location, properties interface{}) feature {
+	return feature{
+		Type: "Feature",
+		ID:   id,
+		Geometry: point{
+			Type:        "Point",
+			Coordinates: [2]float64{location.Longitude, location.Latitude},
+		},
+		Properties: properties,
+	}
+}
+
+func newSearchFeatureCollection(search domain.VenueSearch) featureCollection {
+	collection := featureCollection{
+		Type:     "FeatureCollection",
+		Features: []feature{},
+		Warnings: search.Warnings,
+	}
+	for _, v := range search.Venues {
+		collection.Features = append(collection.Features, newFeature(v.ID, v.Location, venueProperties{
+			Name:     v.Name,
+			Location: newLocationResponse(v.Location),
+			Distance: v.Location.Distance,
+			Bearing:  v.Location.Bearing,
+		}))
+	}
+	return collection
+}
+
+func newDetailsFeatureCollection(venue domain.VenueDetails) featureCollection {
+	return featureCollection{
+		Type: "FeatureCollection",
+		Features: []feature{
+			newFeature(venue.ID, venue.Location, newVenueDetailsResponse(venue)),
+		},
+	}
+}
+
+// sendSearch writes the search results either in our
+// own format or as GeoJSON depending on what the client asked for
+func sendSearch(ctx fiber.Ctx, search domain.VenueSearch, geoJSON bool) error {
+	if geoJSON {
+		return sendGeoJSON(ctx, newSearchFeatureCollection(search))
+	}
+
+	rawJSON, err := json.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("error building venues search response JSON: %s", err)
+	}
+
+	return ctx.Send(rawJSON)
+}
+
+func sendVenueList(ctx fiber.Ctx, venues []domain.Venue) error {
+	if venues == nil {
+		venues = []domain.Venue{}
+	}
+
+	rawJSON, err := json.Marshal(venues)
+	if err != nil {
+		return fmt.Errorf("error building GET venues response JSON: %s", err)
+	}
+
+	return ctx.Send(rawJSON)
+}
+
+func sendGeoJSON(ctx fiber.Ctx, collection featureCollection) error {
+	rawJSON, err := json.Marshal(collection)
+	if err != nil {
+		return fmt.Errorf("error building GeoJSON response: %s", err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, geoJSONContentType)
+	return ctx.Send(rawJSON)
+}
@@ -0,0 +1,124 @@
+package venuesctrl
+
+import "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+
+// The response types below are part of our public API, so they are kept
+// apart from the domain entities, which can change freely when we add
+// new providers or features.
+
+type venueDetailsResponse struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Location    locationResponse   `json:"location"`
+	Categories  []categoryResponse `json:"categories"`
+	Rating      float64            `json:"rating,omitempty"`
+	Hours       hoursResponse      `json:"hours"`
+	Contact     contactResponse    `json:"contact"`
+	Photos      []photoResponse    `json:"photos"`
+}
+
+type locationResponse struct {
+	Latitude         float64  `json:"latitude"`
+	Longitude        float64  `json:"longitude"`
+	Address          string   `json:"address,omitempty"`
+	CrossStreet      string   `json:"cross_street,omitempty"`
+	PostalCode       string   `json:"postal_code,omitempty"`
+	City             string   `json:"city,omitempty"`
+	State            string   `json:"state,omitempty"`
+	Country          string   `json:"country,omitempty"`
+	CountryCode      string   `json:"country_code,omitempty"`
+	FormattedAddress []string `json:"formatted_address"`
+}
+
+type categoryResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type hoursResponse struct {
+	Display string           `json:"display,omitempty"`
+	OpenNow *bool            `json:"open_now"`
+	Periods []periodResponse `json:"periods"`
+}
+
+type periodResponse struct {
+	Day   int    `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+type contactResponse struct {
+	Phone   string `json:"phone,omitempty"`
+	Website string `json:"website,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Twitter string `json:"twitter,omitempty"`
+}
+
+type photoResponse struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+func newVenueDetailsResponse(venue domain.VenueDetails) venueDetailsResponse {
+	// Empty lists are rendered as [] instead of null:
+	resp := venueDetailsResponse{
+		ID:          venue.ID,
+		Name:        venue.Name,
+		Description: venue.Description,
+		Location:    newLocationResponse(venue.Location),
+		Categories:  []categoryResponse{},
+		Rating:      venue.Rating,
+		Hours: hoursResponse{
+			Display: venue.Hours.Display,
+			OpenNow: venue.Hours.OpenNow,
+			Periods: []periodResponse{},
+		},
+		Contact: contactResponse{
+			Phone:   venue.Contact.Phone,
+			Website: venue.Contact.Website,
+			Email:   venue.Contact.Email,
+			Twitter: venue.Contact.Twitter,
+		},
+		Photos: []photoResponse{},
+	}
+
+	for _, c := range venue.Categories {
+		resp.Categories = append(resp.Categories, categoryResponse{
+			ID:   c.ID,
+			Name: c.Name,
+		})
+	}
+	for _, p := range venue.Hours.Periods {
+		resp.Hours.Periods = append(resp.Hours.Periods, periodResponse{
+			Day:   p.Day,
+			Open:  p.Open,
+			Close: p.Close,
+		})
+	}
+	for _, p := range venue.Photos {
+		resp.Photos = append(resp.Photos, photoResponse{
+			URL:    p.URL,
+			Width:  p.Width,
+			Height: p.Height,
+		})
+	}
+
+	return resp
+}
+
+func newLocationResponse(location domain.Location) locationResponse {
+	return locationResponse{
+		Latitude:         location.Latitude,
+		Longitude:        location.Longitude,
+		Address:          location.Address,
+		CrossStreet:      location.CrossStreet,
+		PostalCode:       location.PostalCode,
+		City:             location.City,
+		State:            location.State,
+		Country:          location.Country,
+		CountryCode:      location.CountryCode,
+		FormattedAddress: append([]string{}, location.FormattedAddress...),
+	}
+}
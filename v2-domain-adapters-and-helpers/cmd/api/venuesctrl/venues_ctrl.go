@@ -2,9 +2,11 @@ package venuesctrl
 
 import (
 	"encoding/json"
-	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain/venues"
 )
 
@@ -28,28 +30,221 @@ func NewController(venuesService venues.Service) Controller {
 }
 
 func (c Controller) GetVenuesByCoordinates(ctx fiber.Ctx) error {
-	latitude := ctx.Params("latitude")
-	longitude := ctx.Params("latitude")
+	geoJSON, err := wantsGeoJSON(ctx)
+	if err != nil {
+		return err
+	}
+
+	coordinates, err := domain.ParseCoordinates(ctx.Params("latitude"), ctx.Params("longitude"))
+	if err != nil {
+		return err
+	}
+
+	radius, err := parseIntQuery(ctx, "radius")
+	if err != nil {
+		return err
+	}
+	limit, err := parseIntQuery(ctx, "limit")
+	if err != nil {
+		return err
+	}
+	openNow, err := parseBoolQuery(ctx, "open_now")
+	if err != nil {
+		return err
+	}
+
+	search, err := c.venuesService.GetVenues(ctx.Context(), domain.VenueSearchParams{
+		Coordinates: coordinates,
+		Radius:      radius,
+		Query:       ctx.Query("query"),
+		CategoryIDs: parseListQuery(ctx, "categories"),
+		Limit:       limit,
+		OpenNow:     openNow,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Without extra providers there are never warnings, so we keep
+	// the original format of this endpoint, i.e. a list of venues:
+	if !geoJSON && !c.venuesService.HasExtraProviders() {
+		return sendVenueList(ctx, search.Venues)
+	}
+
+	return sendSearch(ctx, search, geoJSON)
+}
+
+// GetVenuesInArea expects the area as a bounding box on the query string,
+// e.g. `?bbox=<west>,<south>,<east>,<north>`, with the same filters of the
+// search by coordinates except for the radius.
+func (c Controller) GetVenuesInArea(ctx fiber.Ctx) error {
+	geoJSON, err := wantsGeoJSON(ctx)
+	if err != nil {
+		return err
+	}
+
+	bbox, err := domain.ParseBoundingBox(ctx.Query("bbox"))
+	if err != nil {
+		return err
+	}
 
-	venues, err := c.venuesService.GetVenues(ctx.Context(), latitude, longitude)
+	limit, err := parseIntQuery(ctx, "limit")
+	if err != nil {
+		return err
+	}
+	openNow, err := parseBoolQuery(ctx, "open_now")
 	if err != nil {
 		return err
 	}
 
-	rawJSON, err := json.Marshal(venues)
+	search, err := c.venuesService.GetVenuesInArea(ctx.Context(), domain.VenueAreaSearchParams{
+		BoundingBox: bbox,
+		Query:       ctx.Query("query"),
+		CategoryIDs: parseListQuery(ctx, "categories"),
+		Limit:       limit,
+		OpenNow:     openNow,
+	})
 	if err != nil {
-		return fmt.Errorf("error building GET venues response JSON: %s", err)
+		return err
 	}
 
-	return ctx.Send(rawJSON)
+	return sendSearch(ctx, search, geoJSON)
+}
+
+// SearchVenuesInArea is the same as GetVenuesInArea but receives the
+// filters on a JSON body, which allows clipping the results to a polygon:
+//
+//	{
+//	  "bbox": [<west>, <south>, <east>, <north>],
+//	  "polygon": {"type": "Polygon", "coordinates": [[[<lng>, <lat>], ...]]},
+//	  "query": "pizza",
+//	  "categories": ["13065"],
+//	  "limit": 100,
+//	  "open_now": true
+//	}
+//
+// Only one of the bbox or the polygon is required.
+func (c Controller) SearchVenuesInArea(ctx fiber.Ctx) error {
+	geoJSON, err := wantsGeoJSON(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req struct {
+		BBox       []float64        `json:"bbox"`
+		Polygon    *geoJSONGeometry `json:"polygon"`
+		Query      string           `json:"query"`
+		Categories []string         `json:"categories"`
+		Limit      int              `json:"limit"`
+		OpenNow    bool             `json:"open_now"`
+	}
+	err = json.Unmarshal(ctx.Body(), &req)
+	if err != nil {
+		return domain.BadRequestErr("unable to parse payload as JSON", map[string]interface{}{
+			"payload": string(ctx.Body()),
+			"error":   err.Error(),
+		})
+	}
+
+	var bbox domain.BoundingBox
+	if req.BBox != nil {
+		if len(req.BBox) != 4 {
+			return domain.BadRequestErr("invalid-bounding-box", map[string]interface{}{
+				"received": req.BBox,
+				"expected": "[<west>, <south>, <east>, <north>]",
+			})
+		}
+		bbox = domain.BoundingBox{West: req.BBox[0], South: req.BBox[1], East: req.BBox[2], North: req.BBox[3]}
+	}
+
+	var polygon domain.Polygon
+	if req.Polygon != nil {
+		polygon, err = req.Polygon.toPolygon()
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.BBox == nil && req.Polygon == nil {
+		return domain.BadRequestErr("missing-search-area", map[string]interface{}{
+			"expected": "a bbox, a polygon or both",
+		})
+	}
+
+	search, err := c.venuesService.GetVenuesInArea(ctx.Context(), domain.VenueAreaSearchParams{
+		BoundingBox: bbox,
+		Polygon:     polygon,
+		Query:       req.Query,
+		CategoryIDs: req.Categories,
+		Limit:       req.Limit,
+		OpenNow:     req.OpenNow,
+	})
+	if err != nil {
+		return err
+	}
+
+	return sendSearch(ctx, search, geoJSON)
 }
 
 func (c Controller) GetDetails(ctx fiber.Ctx) error {
+	geoJSON, err := wantsGeoJSON(ctx)
+	if err != nil {
+		return err
+	}
+
 	id := ctx.Params("id")
 	venue, err := c.venuesService.GetVenue(ctx.Context(), id)
 	if err != nil {
 		return err
 	}
 
-	return ctx.Send(venue)
+	if geoJSON {
+		return sendGeoJSON(ctx, newDetailsFeatureCollection(venue))
+	}
+	return ctx.JSON(newVenueDetailsResponse(venue))
+}
+
+// parseListQuery parses comma separated lists, e.g. `?categories=13064,13065`
+func parseListQuery(ctx fiber.Ctx, name string) []string {
+	var values []string
+	for _, value := range strings.Split(ctx.Query(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseIntQuery(ctx fiber.Ctx, name string) (int, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, domain.BadRequestErr("invalid-query-param", map[string]interface{}{
+			"param":    name,
+			"received": value,
+			"expected": "an integer",
+		})
+	}
+	return i, nil
+}
+
+func parseBoolQuery(ctx fiber.Ctx, name string) (bool, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, domain.BadRequestErr("invalid-query-param", map[string]interface{}{
+			"param":    name,
+			"received": value,
+			"expected": "true or false",
+		})
+	}
+	return b, nil
 }
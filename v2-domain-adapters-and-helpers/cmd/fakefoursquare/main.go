@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log/jsonlogs"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/env"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/fakefoursquare"
+)
+
+// This program serves a fake Foursquare API for running
+// the venues API locally without real credentials, e.g.:
+//
+//	PORT=8766 go run ./cmd/fakefoursquare
+//	FOURSQUARE_BASE_URL=http://localhost:8766/v2 go run ./cmd/api
+func main() {
+	ctx := context.Background()
+
+	// Read all configs at once so its easy to spot all of them:
+	port := env.GetString("PORT", "8766")
+	logLevel := env.GetString("LOG_LEVEL", "INFO")
+	fixturesFile := env.GetString("FAKE_FOURSQUARE_FIXTURES", "")
+	clientID := env.GetString("FOURSQUARE_CLIENT_ID", "")
+	secret := env.GetString("FOURSQUARE_SECRET", "")
+	latency := env.GetDuration("FAKE_FOURSQUARE_LATENCY", 0)
+	errorRate := env.GetFloat("FAKE_FOURSQUARE_ERROR_RATE", 0)
+	errorStatus := env.GetInt("FAKE_FOURSQUARE_ERROR_STATUS", 500)
+
+	logger := jsonlogs.New(logLevel, domain.GetCtxValues)
+
+	var venues []fakefoursquare.Venue
+	if fixturesFile != "" {
+		rawJSON, err := os.ReadFile(fixturesFile)
+		if err != nil {
+			logger.Fatal(ctx, "unable-to-read-fixtures-file", log.Body{
+				"file":  fixturesFile,
+				"error": err.Error(),
+			})
+		}
+
+		venues, err = fakefoursquare.ParseVenues(rawJSON)
+		if err != nil {
+			logger.Fatal(ctx, "unable-to-parse-fixtures-file", log.Body{
+				"file":  fixturesFile,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	server := fakefoursquare.New(fakefoursquare.Config{
+		Venues:       venues,
+		ClientID:     clientID,
+		ClientSecret: secret,
+		Latency:      latency,
+		ErrorRate:    errorRate,
+		ErrorStatus:  errorStatus,
+	})
+
+	logger.Info(ctx, "fake-foursquare-starting-up", log.Body{
+		"port":       port,
+		"latency_ms": latency.Milliseconds(),
+		"error_rate": errorRate,
+	})
+
+	err := http.ListenAndServe(":"+port, server)
+	logger.Error(ctx, "fake-foursquare-stopped-with-an-error", log.Body{
+		"error": err.Error(),
+	})
+}
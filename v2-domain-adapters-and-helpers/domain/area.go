@@ -0,0 +1,133 @@
+package domain
+
+import "strings"
+
+// BoundingBox is the area between two parallels and two meridians
+// in decimal degrees, boxes crossing the antimeridian are not supported.
+type BoundingBox struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+}
+
+// ParseBoundingBox parses a bounding box in the GeoJSON order,
+// i.e. "<west>,<south>,<east>,<north>", e.g. "-73.995,40.74,-73.975,40.76".
+func ParseBoundingBox(bbox string) (BoundingBox, error) {
+	edges := strings.Split(bbox, ",")
+	if len(edges) != 4 {
+		return BoundingBox{}, BadRequestErr("invalid-bounding-box", map[string]interface{}{
+			"received": bbox,
+			"expected": "<west>,<south>,<east>,<north>",
+		})
+	}
+
+	fieldErrs := map[string]string{}
+	parseEdge := func(name string, value string, maxDegrees float64) float64 {
+		degrees, err := parseDecimalDegrees(strings.TrimSpace(value), maxDegrees)
+		if err != "" {
+			fieldErrs[name] = err
+		}
+		return roundDegrees(degrees)
+	}
+
+	box := BoundingBox{
+		West:  parseEdge("west", edges[0], 180),
+		South: parseEdge("south", edges[1], 90),
+		East:  parseEdge("east", edges[2], 180),
+		North: parseEdge("north", edges[3], 90),
+	}
+	if len(fieldErrs) > 0 {
+		return BoundingBox{}, BadRequestErr("invalid-bounding-box", map[string]interface{}{
+			"fields": fieldErrs,
+		})
+	}
+
+	return box, box.Validate()
+}
+
+// Validate checks the ranges of the edges and that they are not swapped
+func (b BoundingBox) Validate() error {
+	fieldErrs := map[string]string{}
+	if err := checkRange(b.South, 90); err != "" {
+		fieldErrs["south"] = err
+	}
+	if err := checkRange(b.West, 180); err != "" {
+		fieldErrs["west"] = err
+	}
+	if err := checkRange(b.North, 90); err != "" {
+		fieldErrs["north"] = err
+	}
+	if err := checkRange(b.East, 180); err != "" {
+		fieldErrs["east"] = err
+	}
+	if len(fieldErrs) == 0 && b.South > b.North {
+		fieldErrs["north"] = "must not be smaller than the south"
+	}
+	if len(fieldErrs) == 0 && b.West > b.East {
+		fieldErrs["east"] = "must not be smaller than the west"
+	}
+
+	if len(fieldErrs) > 0 {
+		return BadRequestErr("invalid-bounding-box", map[string]interface{}{
+			"fields": fieldErrs,
+		})
+	}
+	return nil
+}
+
+// Polygon is a list of linear rings, as in GeoJSON the first one is the
+// boundary of the polygon and the others are holes inside it.
+type Polygon [][]Coordinates
+
+// Validate checks that the polygon has a boundary and that
+// all of its rings are closed and have valid coordinates
+func (p Polygon) Validate() error {
+	if len(p) == 0 {
+		return BadRequestErr("invalid-polygon", map[string]interface{}{
+			"reason": "the polygon must have at least one ring",
+		})
+	}
+
+	for i, ring := range p {
+		if len(ring) < 4 {
+			return BadRequestErr("invalid-polygon", map[string]interface{}{
+				"ring":   i,
+				"reason": "the rings must have at least 4 positions",
+			})
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return BadRequestErr("invalid-polygon", map[string]interface{}{
+				"ring":   i,
+				"reason": "the first and last positions of the rings must be the same",
+			})
+		}
+		for _, coordinates := range ring {
+			if err := coordinates.Validate(); err != nil {
+				return BadRequestErr("invalid-polygon", map[string]interface{}{
+					"ring":   i,
+					"reason": "invalid coordinates " + coordinates.String(),
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// BoundingBox returns the smallest box containing the boundary of the polygon
+func (p Polygon) BoundingBox() BoundingBox {
+	if len(p) == 0 || len(p[0]) == 0 {
+		return BoundingBox{}
+	}
+
+	first := p[0][0]
+	box := BoundingBox{South: first.Latitude, West: first.Longitude, North: first.Latitude, East: first.Longitude}
+	for _, c := range p[0] {
+		box.South = min(box.South, c.Latitude)
+		box.West = min(box.West, c.Longitude)
+		box.North = max(box.North, c.Latitude)
+		box.East = max(box.East, c.Longitude)
+	}
+	return box
+}
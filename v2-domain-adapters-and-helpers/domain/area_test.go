@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		desc              string
+		bbox              string
+		expectedBox       BoundingBox
+		expectedErrTitle  string
+		expectedFieldErrs map[string]string
+	}{
+		{
+			desc:        "should parse the edges in the GeoJSON order",
+			bbox:        "-73.995,40.74,-73.975, 40.76",
+			expectedBox: BoundingBox{South: 40.74, West: -73.995, North: 40.76, East: -73.975},
+		},
+		{
+			desc:             "should reject the wrong number of edges",
+			bbox:             "-73.995,40.74,-73.975",
+			expectedErrTitle: "invalid-bounding-box",
+		},
+		{
+			desc:             "should report the errors of each edge",
+			bbox:             "-181,40.74,abc,40.76",
+			expectedErrTitle: "invalid-bounding-box",
+			expectedFieldErrs: map[string]string{
+				"west": "must be between -180 and 180",
+				"east": "must be a decimal number, e.g. -73.9857",
+			},
+		},
+		{
+			desc:             "should reject swapped edges",
+			bbox:             "-73.995,40.76,-73.975,40.74",
+			expectedErrTitle: "invalid-bounding-box",
+			expectedFieldErrs: map[string]string{
+				"north": "must not be smaller than the south",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			box, err := ParseBoundingBox(test.bbox)
+			if test.expectedErrTitle == "" {
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, box, test.expectedBox)
+				return
+			}
+
+			domainErr := AsDomainErr(err)
+			tt.AssertEqual(t, domainErr.Code, "BadRequestErr")
+			tt.AssertEqual(t, domainErr.Title, test.expectedErrTitle)
+			if test.expectedFieldErrs != nil {
+				tt.AssertEqual(t, domainErr.Data["fields"], test.expectedFieldErrs)
+			}
+		})
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	triangle := Polygon{{
+		{Latitude: 40.74, Longitude: -73.99},
+		{Latitude: 40.76, Longitude: -73.98},
+		{Latitude: 40.75, Longitude: -73.97},
+		{Latitude: 40.74, Longitude: -73.99},
+	}}
+
+	t.Run("should return the bounding box of the boundary", func(t *testing.T) {
+		tt.AssertEqual(t, triangle.BoundingBox(), BoundingBox{South: 40.74, West: -73.99, North: 40.76, East: -73.97})
+	})
+
+	tests := []struct {
+		desc           string
+		polygon        Polygon
+		expectedReason string
+	}{
+		{
+			desc:    "should accept valid polygons",
+			polygon: triangle,
+		},
+		{
+			desc:           "should reject polygons without rings",
+			polygon:        Polygon{},
+			expectedReason: "at least one ring",
+		},
+		{
+			desc:           "should reject rings that are too short",
+			polygon:        Polygon{triangle[0][1:]},
+			expectedReason: "at least 4 positions",
+		},
+		{
+			desc:           "should reject rings that are not closed",
+			polygon:        Polygon{append(triangle[0][:3:3], Coordinates{Latitude: 40.7, Longitude: -73.9})},
+			expectedReason: "must be the same",
+		},
+		{
+			desc: "should reject invalid coordinates",
+			polygon: Polygon{{
+				{Latitude: 0, Longitude: 0},
+				{Latitude: 91, Longitude: 0},
+				{Latitude: 0, Longitude: 1},
+				{Latitude: 0, Longitude: 0},
+			}},
+			expectedReason: "invalid coordinates 91,0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.polygon.Validate()
+			if test.expectedReason == "" {
+				tt.AssertNoErr(t, err)
+				return
+			}
+
+			domainErr := AsDomainErr(err)
+			tt.AssertEqual(t, domainErr.Title, "invalid-polygon")
+			tt.AssertContains(t, domainErr.Data["reason"].(string), test.expectedReason)
+		})
+	}
+}
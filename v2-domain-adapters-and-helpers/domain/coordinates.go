@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// CoordinatesPrecision is the number of decimal places kept
+// on the coordinates, which is enough for ~10cm of precision.
+const CoordinatesPrecision = 6
+
+// Coordinates is a point on the globe in decimal degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Only plain decimal numbers are accepted, which rules out
+// inputs such as "NaN", "Inf", "1e3" or "0x1p-2" that strconv would parse:
+var decimalDegreesRegex = regexp.MustCompile(`^[+-]?[0-9]{1,3}(\.[0-9]+)?$`)
+
+// ParseCoordinates parses coordinates in decimal degrees, e.g. "40.7484" and "-73.9857",
+// rounding them to the CoordinatesPrecision.
+//
+// The BadRequestErr returned for invalid input lists the error of each field.
+func ParseCoordinates(latitude string, longitude string) (Coordinates, error) {
+	fieldErrs := map[string]string{}
+
+	lat, err := parseDecimalDegrees(latitude, 90)
+	if err != "" {
+		fieldErrs["latitude"] = err
+	}
+	lng, err := parseDecimalDegrees(longitude, 180)
+	if err != "" {
+		fieldErrs["longitude"] = err
+	}
+
+	if len(fieldErrs) > 0 {
+		return Coordinates{}, BadRequestErr("invalid-coordinates", map[string]interface{}{
+			"fields": fieldErrs,
+		})
+	}
+
+	return Coordinates{
+		Latitude:  roundDegrees(lat),
+		Longitude: roundDegrees(lng),
+	}, nil
+}
+
+func parseDecimalDegrees(value string, maxDegrees float64) (float64, string) {
+	if value == "" {
+		return 0, "is required"
+	}
+	if !decimalDegreesRegex.MatchString(value) {
+		return 0, "must be a decimal number, e.g. -73.9857"
+	}
+
+	degrees, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, "must be a decimal number, e.g. -73.9857"
+	}
+	return degrees, checkRange(degrees, maxDegrees)
+}
+
+func checkRange(degrees float64, maxDegrees float64) string {
+	if !(degrees >= -maxDegrees && degrees <= maxDegrees) {
+		return "must be between " + strconv.Itoa(int(-maxDegrees)) + " and " + strconv.Itoa(int(maxDegrees))
+	}
+	return ""
+}
+
+// Validate checks the ranges of the latitude and longitude
+func (c Coordinates) Validate() error {
+	fieldErrs := map[string]string{}
+	if err := checkRange(c.Latitude, 90); err != "" {
+		fieldErrs["latitude"] = err
+	}
+	if err := checkRange(c.Longitude, 180); err != "" {
+		fieldErrs["longitude"] = err
+	}
+
+	if len(fieldErrs) > 0 {
+		return BadRequestErr("invalid-coordinates", map[string]interface{}{
+			"fields": fieldErrs,
+		})
+	}
+	return nil
+}
+
+// LatitudeString formats the latitude without trailing zeros, e.g. "40.7484"
+func (c Coordinates) LatitudeString() string {
+	return strconv.FormatFloat(roundDegrees(c.Latitude), 'f', -1, 64)
+}
+
+// LongitudeString formats the longitude without trailing zeros, e.g. "-73.9857"
+func (c Coordinates) LongitudeString() string {
+	return strconv.FormatFloat(roundDegrees(c.Longitude), 'f', -1, 64)
+}
+
+// String formats the coordinates as "<latitude>,<longitude>"
+func (c Coordinates) String() string {
+	return c.LatitudeString() + "," + c.LongitudeString()
+}
+
+func roundDegrees(degrees float64) float64 {
+	scale := math.Pow(10, CoordinatesPrecision)
+	return math.Round(degrees*scale) / scale
+}
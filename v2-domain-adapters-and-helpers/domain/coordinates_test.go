@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	tests := []struct {
+		desc              string
+		latitude          string
+		longitude         string
+		expectedCoords    Coordinates
+		expectedFieldErrs map[string]string
+	}{
+		{
+			desc:           "should parse decimal degrees",
+			latitude:       "40.7484",
+			longitude:      "-73.9857",
+			expectedCoords: Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		},
+		{
+			desc:           "should round to the coordinates precision",
+			latitude:       "40.748441234",
+			longitude:      "+73.98566",
+			expectedCoords: Coordinates{Latitude: 40.748441, Longitude: 73.98566},
+		},
+		{
+			desc:      "should report the errors of each field",
+			latitude:  "91",
+			longitude: "abc",
+			expectedFieldErrs: map[string]string{
+				"latitude":  "must be between -90 and 90",
+				"longitude": "must be a decimal number, e.g. -73.9857",
+			},
+		},
+		{
+			desc:      "should reject formats accepted by strconv",
+			latitude:  "NaN",
+			longitude: "1e2",
+			expectedFieldErrs: map[string]string{
+				"latitude":  "must be a decimal number, e.g. -73.9857",
+				"longitude": "must be a decimal number, e.g. -73.9857",
+			},
+		},
+		{
+			desc:      "should reject empty values",
+			latitude:  "",
+			longitude: "180.0",
+			expectedFieldErrs: map[string]string{
+				"latitude": "is required",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			coords, err := ParseCoordinates(test.latitude, test.longitude)
+			if test.expectedFieldErrs == nil {
+				tt.AssertNoErr(t, err)
+				tt.AssertEqual(t, coords, test.expectedCoords)
+				return
+			}
+
+			domainErr := AsDomainErr(err)
+			tt.AssertEqual(t, domainErr.Code, "BadRequestErr")
+			tt.AssertEqual(t, domainErr.Title, "invalid-coordinates")
+			tt.AssertEqual(t, domainErr.Data["fields"], test.expectedFieldErrs)
+		})
+	}
+}
+
+func TestCoordinatesString(t *testing.T) {
+	t.Run("should format the coordinates without trailing zeros", func(t *testing.T) {
+		tt.AssertEqual(t, Coordinates{Latitude: 40.7484, Longitude: -73.98570001}.String(), "40.7484,-73.9857")
+	})
+}
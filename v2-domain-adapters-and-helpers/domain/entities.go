@@ -8,6 +8,110 @@ type Venue struct {
 	Location Location
 }
 
+// VenueSearchParams describes a search for venues around a point,
+// all fields except for the coordinates are optional.
+type VenueSearchParams struct {
+	Coordinates Coordinates
+
+	// Radius in meters
+	Radius int
+
+	// Query filters the venues by name
+	Query string
+
+	// CategoryIDs are the IDs of the categories in
+	// the format expected by the venue provider
+	CategoryIDs []string
+
+	// Limit is the maximum number of venues returned
+	Limit int
+
+	OpenNow bool
+}
+
+// VenueAreaSearchParams describes a search for the venues inside an area,
+// e.g. the area visible on a map, all fields except for the box are optional.
+type VenueAreaSearchParams struct {
+	BoundingBox BoundingBox
+
+	// Polygon restricts the results to a more precise
+	// shape inside the bounding box
+	Polygon Polygon
+
+	Query       string
+	CategoryIDs []string
+	Limit       int
+	OpenNow     bool
+}
+
+// VenueSearch is the result of a search on one or more venue providers
+type VenueSearch struct {
+	Venues []Venue `json:"venues"`
+
+	// Warnings list the providers that failed when the others succeeded
+	Warnings []SearchWarning `json:"warnings,omitempty"`
+}
+
+type SearchWarning struct {
+	Provider string `json:"provider,omitempty"`
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+}
+
+// VenueDetails describes a venue in the same way for all providers,
+// the fields unknown to the provider are left empty.
+type VenueDetails struct {
+	ID          string
+	Name        string
+	Description string
+	Location    Location
+	Categories  []Category
+
+	// Rating goes from 0 to 10, 0 means the venue has no rating
+	Rating float64
+
+	Hours   OpeningHours
+	Contact Contact
+	Photos  []Photo
+}
+
+type Category struct {
+	ID   string
+	Name string
+}
+
+type OpeningHours struct {
+	// Display is a human readable description, e.g. "Mon-Sun 8:00 AM-2:00 AM"
+	Display string
+
+	// OpenNow is nil when the provider doesn't know it
+	OpenNow *bool
+
+	Periods []OpeningPeriod
+}
+
+type OpeningPeriod struct {
+	// Day goes from 1 (Monday) to 7 (Sunday)
+	Day int
+
+	// Open and Close use the "HHMM" 24h format, e.g. "0800" and "2200"
+	Open  string
+	Close string
+}
+
+type Contact struct {
+	Phone   string
+	Website string
+	Email   string
+	Twitter string
+}
+
+type Photo struct {
+	URL    string
+	Width  int
+	Height int
+}
+
 type Location struct {
 	Address          string          `json:"address"`
 	CrossStreet      string          `json:"crossStreet"`
@@ -15,6 +119,7 @@ type Location struct {
 	Longitude        float64         `json:"lng"`
 	LabeledLatLngs   []LabeledCoords `json:"labeledLatLngs"`
 	Distance         int             `json:"distance"`
+	Bearing          float64         `json:"bearing"`
 	PostalCode       string          `json:"postalCode"`
 	CountryCode      string          `json:"cc"`
 	City             string          `json:"city"`
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -22,11 +23,25 @@ func (e DomainErr) Error() string {
 	return strings.Join(fields, "; ")
 }
 
+// DomainErrConverter can be implemented by errors from other packages
+// that know how to describe themselves as domain errors, e.g. `rest.StatusError`.
+type DomainErrConverter interface {
+	DomainErr() DomainErr
+}
+
 func AsDomainErr(err error) DomainErr {
-	domainErr, ok := err.(DomainErr)
-	if ok {
+	// Domain errors might be wrapped, e.g. when returned from an
+	// http.RoundTripper they are wrapped in an *url.Error:
+	var domainErr DomainErr
+	if errors.As(err, &domainErr) {
 		return domainErr
 	}
+
+	var converter DomainErrConverter
+	if errors.As(err, &converter) {
+		return converter.DomainErr()
+	}
+
 	return DomainErr{
 		Code:  "InternalErr",
 		Title: err.Error(),
@@ -64,3 +79,19 @@ func NotFoundErr(title string, data map[string]interface{}) DomainErr {
 		Data:  data,
 	}
 }
+
+func UnavailableErr(title string, data map[string]interface{}) DomainErr {
+	return DomainErr{
+		Code:  "UnavailableErr",
+		Title: title,
+		Data:  data,
+	}
+}
+
+func RateLimitedErr(title string, data map[string]interface{}) DomainErr {
+	return DomainErr{
+		Code:  "RateLimitedErr",
+		Title: title,
+		Data:  data,
+	}
+}
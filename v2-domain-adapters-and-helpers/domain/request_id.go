@@ -9,6 +9,10 @@ import (
 // RequestIDKey needs to be a string, since its used on Fiber `.Locals()`
 const RequestIDKey string = "request_id_key"
 
+// TraceParentKey is used for storing the W3C `traceparent` header
+// received on the request, so it can be forwarded to other services.
+const TraceParentKey string = "traceparent_key"
+
 func GenerateRequestID() string {
 	return uuid.NewString()
 }
@@ -17,3 +21,8 @@ func GetRequestIDFromContext(ctx context.Context) string {
 	requestID, _ := ctx.Value(RequestIDKey).(string)
 	return requestID
 }
+
+func GetTraceParentFromContext(ctx context.Context) string {
+	traceParent, _ := ctx.Value(TraceParentKey).(string)
+	return traceParent
+}
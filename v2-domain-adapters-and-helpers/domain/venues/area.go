@@ -0,0 +1,173 @@
+package venues
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/geo"
+)
+
+// GetVenuesInArea searches the venues inside a bounding box and, optionally,
+// inside a polygon. If only the polygon is given its bounding box is used.
+//
+// The area is split into a grid of provider searches whose results are merged
+// and clipped to the area, the venues are sorted by the distance to its center.
+func (s Service) GetVenuesInArea(ctx context.Context, params domain.VenueAreaSearchParams) (domain.VenueSearch, error) {
+	if params.BoundingBox == (domain.BoundingBox{}) && len(params.Polygon) > 0 {
+		params.BoundingBox = params.Polygon.BoundingBox()
+	}
+
+	err := validateAreaSearchParams(params)
+	if err != nil {
+		return domain.VenueSearch{}, err
+	}
+
+	box := geo.Box{
+		MinLat: params.BoundingBox.South,
+		MinLng: params.BoundingBox.West,
+		MaxLat: params.BoundingBox.North,
+		MaxLng: params.BoundingBox.East,
+	}
+
+	rows := max(1, int(math.Ceil(box.Height()/float64(s.config.AreaCellSize))))
+	cols := max(1, int(math.Ceil(box.Width()/float64(s.config.AreaCellSize))))
+	if rows*cols > s.config.MaxAreaQueries {
+		return domain.VenueSearch{}, domain.BadRequestErr("search-area-too-large", map[string]interface{}{
+			"width":     int(box.Width()),
+			"height":    int(box.Height()),
+			"cells":     rows * cols,
+			"max_cells": s.config.MaxAreaQueries,
+			"cell_size": s.config.AreaCellSize,
+		})
+	}
+
+	cells := box.Split(rows, cols)
+
+	type result struct {
+		search domain.VenueSearch
+		err    error
+	}
+	results := make([]result, len(cells))
+
+	// Once a provider rate limits us the searches of
+	// the remaining cells would fail too, so we skip them:
+	var rateLimitMutex sync.Mutex
+	var rateLimitErr error
+
+	// Each cell is searched on all the providers, so the
+	// concurrency is limited to avoid flooding them:
+	semaphore := make(chan struct{}, s.config.MaxAreaConcurrency)
+
+	var wg sync.WaitGroup
+	for i, cell := range cells {
+		wg.Add(1)
+		go func(i int, cell geo.Box) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			rateLimitMutex.Lock()
+			skipErr := rateLimitErr
+			rateLimitMutex.Unlock()
+			if skipErr != nil {
+				results[i] = result{err: skipErr}
+				return
+			}
+
+			lat, lng := cell.Center()
+			search, err := s.search(ctx, domain.VenueSearchParams{
+				Coordinates: domain.Coordinates{Latitude: lat, Longitude: lng},
+				Radius:      int(math.Ceil(cell.Radius())),
+				Query:       params.Query,
+				CategoryIDs: params.CategoryIDs,
+				Limit:       MaxSearchLimit,
+				OpenNow:     params.OpenNow,
+			})
+			if err != nil && domain.AsDomainErr(err).Code == "RateLimitedErr" {
+				rateLimitMutex.Lock()
+				rateLimitErr = err
+				rateLimitMutex.Unlock()
+			}
+			results[i] = result{search: search, err: err}
+		}(i, cell)
+	}
+	wg.Wait()
+
+	var area domain.VenueSearch
+	var firstErr error
+	var failed int
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+
+		// The cells overlap near their corners, so the same venue
+		// might be found on more than one of them:
+		area.Venues = mergeVenues(area.Venues, r.search.Venues, s.config.DedupMaxDistance, s.config.DedupMinSimilarity)
+		for _, warning := range r.search.Warnings {
+			area.Warnings = appendWarning(area.Warnings, warning)
+		}
+	}
+
+	// The errors are already logged and converted
+	// to meaningful domain errors by the search:
+	if failed == len(results) {
+		return domain.VenueSearch{}, firstErr
+	}
+	if failed > 0 {
+		area.Warnings = appendWarning(area.Warnings, domain.SearchWarning{
+			Code:  domain.AsDomainErr(firstErr).Code,
+			Title: "venue-area-partially-searched",
+		})
+	}
+
+	area.Venues = clipVenues(area.Venues, box, params.Polygon)
+
+	centerLat, centerLng := box.Center()
+	area.Venues = sortByDistance(area.Venues, domain.Coordinates{Latitude: centerLat, Longitude: centerLng}, 0)
+
+	if params.Limit > 0 && len(area.Venues) > params.Limit {
+		area.Venues = area.Venues[:params.Limit]
+	}
+	return area, nil
+}
+
+// clipVenues keeps only the venues inside the box and the polygon, if there is one
+func clipVenues(venues []domain.Venue, box geo.Box, polygon domain.Polygon) []domain.Venue {
+	var rings [][]geo.Point
+	for _, ring := range polygon {
+		points := make([]geo.Point, 0, len(ring))
+		for _, c := range ring {
+			points = append(points, geo.Point{Lat: c.Latitude, Lng: c.Longitude})
+		}
+		rings = append(rings, points)
+	}
+
+	var clipped []domain.Venue
+	for _, v := range venues {
+		lat, lng := v.Location.Latitude, v.Location.Longitude
+		if !box.Contains(lat, lng) {
+			continue
+		}
+		if len(rings) > 0 && !geo.InPolygon(lat, lng, rings) {
+			continue
+		}
+		clipped = append(clipped, v)
+	}
+	return clipped
+}
+
+func appendWarning(warnings []domain.SearchWarning, warning domain.SearchWarning) []domain.SearchWarning {
+	for _, w := range warnings {
+		if w == warning {
+			return warnings
+		}
+	}
+	return append(warnings, warning)
+}
@@ -0,0 +1,221 @@
+package venues
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestGetVenuesInArea(t *testing.T) {
+	ctx := context.Background()
+
+	empireState := domain.Venue{
+		ID:       "fakeEmpireStateID",
+		Name:     "Empire State Building",
+		Location: domain.Location{Latitude: 40.748441, Longitude: -73.985664},
+	}
+	macys := domain.Venue{
+		ID:       "fakeMacysID",
+		Name:     "Macy's Herald Square",
+		Location: domain.Location{Latitude: 40.750824, Longitude: -73.988756},
+	}
+	centralPark := domain.Venue{
+		ID:       "fakeCentralParkID",
+		Name:     "Central Park",
+		Location: domain.Location{Latitude: 40.7827, Longitude: -73.965355},
+	}
+
+	// About 2.2km x 1.7km around the Empire State Building:
+	midtown := domain.BoundingBox{South: 40.74, West: -73.995, North: 40.76, East: -73.975}
+
+	t.Run("should split the area into provider searches and clip the results to it", func(t *testing.T) {
+		var mutex sync.Mutex
+		var paramsArgs []domain.VenueSearchParams
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				paramsArgs = append(paramsArgs, params)
+				return []domain.Venue{centralPark, macys, empireState}, nil
+			},
+		}, cache.Mock{}, Config{
+			AreaCellSize: 1000,
+		})
+
+		search, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+			Query:       "fakeQuery",
+		})
+		tt.AssertNoErr(t, err)
+
+		// The venues found by several cells are merged and sorted by the distance to the center:
+		tt.AssertEqual(t, len(search.Venues), 2)
+		tt.AssertEqual(t, search.Venues[0].ID, "fakeEmpireStateID")
+		tt.AssertEqual(t, search.Venues[1].ID, "fakeMacysID")
+		tt.AssertEqual(t, len(search.Warnings), 0)
+
+		// 3 rows x 2 columns of cells of up to 1000m x 1000m:
+		tt.AssertEqual(t, len(paramsArgs), 6)
+		for _, params := range paramsArgs {
+			tt.AssertTrue(t, params.Radius > 500 && params.Radius < 710)
+			tt.AssertEqual(t, params.Query, "fakeQuery")
+			tt.AssertEqual(t, params.Limit, MaxSearchLimit)
+		}
+	})
+
+	t.Run("should clip the results to the polygon", func(t *testing.T) {
+		// Inside the bounding box of the polygon but not inside the polygon:
+		corner := domain.Venue{
+			ID:       "fakeCornerID",
+			Name:     "fakeCornerName",
+			Location: domain.Location{Latitude: 40.7495, Longitude: -73.9895},
+		}
+
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return []domain.Venue{centralPark, macys, corner, empireState}, nil
+			},
+		}, cache.Mock{}, Config{})
+
+		// A triangle containing the Empire State Building,
+		// the bounding box is taken from the polygon:
+		search, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			Polygon: domain.Polygon{{
+				{Latitude: 40.746, Longitude: -73.99},
+				{Latitude: 40.750, Longitude: -73.985},
+				{Latitude: 40.746, Longitude: -73.98},
+				{Latitude: 40.746, Longitude: -73.99},
+			}},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 1)
+		tt.AssertEqual(t, search.Venues[0].ID, "fakeEmpireStateID")
+	})
+
+	t.Run("should return the venues of the cells that succeeded with a warning", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				// Only the southern cells succeed:
+				if params.Coordinates.Latitude > 40.75 {
+					return nil, domain.UnavailableErr("upstream-service-unavailable", nil)
+				}
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{}, Config{
+			AreaCellSize: 1000,
+		})
+
+		search, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 1)
+		tt.AssertEqual(t, search.Warnings, []domain.SearchWarning{{
+			Code:  "UnavailableErr",
+			Title: "venue-area-partially-searched",
+		}})
+	})
+
+	t.Run("should fail if all the cells fail", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return nil, errors.New("fakeErrMsg")
+			},
+		}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "InternalErr")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "error-retrieving-venues-from-provider")
+	})
+
+	t.Run("should limit the number of cells searched at the same time", func(t *testing.T) {
+		var mutex sync.Mutex
+		var running, maxRunning, calls int
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				mutex.Lock()
+				calls++
+				running++
+				maxRunning = max(maxRunning, running)
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{}, Config{
+			AreaCellSize:       1000,
+			MaxAreaConcurrency: 2,
+		})
+
+		_, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, calls, 6)
+		tt.AssertEqual(t, maxRunning, 2)
+	})
+
+	t.Run("should stop searching the cells once the provider is rate limited", func(t *testing.T) {
+		var mutex sync.Mutex
+		var calls int
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls++
+				return nil, domain.RateLimitedErr("too-many-requests-to-upstream-service", nil)
+			},
+		}, cache.Mock{}, Config{
+			AreaCellSize:       1000,
+			MaxAreaConcurrency: 1,
+		})
+
+		_, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "RateLimitedErr")
+		tt.AssertEqual(t, calls, 1)
+	})
+
+	t.Run("should reject areas that need too many provider searches", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{}, cache.Mock{}, Config{
+			AreaCellSize:   1000,
+			MaxAreaQueries: 4,
+		})
+
+		_, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "BadRequestErr")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "search-area-too-large")
+	})
+
+	t.Run("should validate the params", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: midtown,
+			Limit:       MaxAreaSearchLimit + 1,
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "invalid-search-limit")
+
+		_, err = svc.GetVenuesInArea(ctx, domain.VenueAreaSearchParams{
+			BoundingBox: domain.BoundingBox{South: 40.76, West: -73.995, North: 40.74, East: -73.975},
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "invalid-bounding-box")
+	})
+}
@@ -0,0 +1,95 @@
+package venues
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/geo"
+)
+
+// mergeVenues appends the new venues to the merged ones skipping
+// the venues that refer to a place that was already merged,
+// so the venues of the first providers are always preferred.
+func mergeVenues(merged []domain.Venue, newVenues []domain.Venue, maxDistance float64, minSimilarity float64) []domain.Venue {
+	alreadyMerged := len(merged)
+	for _, v := range newVenues {
+		if !containsPlace(merged[:alreadyMerged], v, maxDistance, minSimilarity) {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+func containsPlace(venues []domain.Venue, v domain.Venue, maxDistance float64, minSimilarity float64) bool {
+	for _, other := range venues {
+		distance := geo.Distance(
+			v.Location.Latitude, v.Location.Longitude,
+			other.Location.Latitude, other.Location.Longitude,
+		)
+		if distance <= maxDistance && nameSimilarity(v.Name, other.Name) >= minSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+// nameSimilarity returns a number between 0 and 1 based on the
+// edit distance of the names after normalizing them, e.g.
+// "The Empire State Building" and "Empire State Bldg." are similar.
+func nameSimilarity(name1 string, name2 string) float64 {
+	a := []rune(normalizeName(name1))
+	b := []rune(normalizeName(name2))
+
+	maxLen := len(a)
+	if len(b) > maxLen {
+		maxLen = len(b)
+	}
+	if maxLen == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(a, b))/float64(maxLen)
+}
+
+// normalizeName lowercases the name, removes punctuation
+// and leading articles and collapses the spaces
+func normalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		if r == '&' {
+			return -1
+		}
+		return ' '
+	}, name)
+
+	words := strings.Fields(name)
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a") {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
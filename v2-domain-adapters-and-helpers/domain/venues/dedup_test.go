@@ -0,0 +1,60 @@
+package venues
+
+import (
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		desc          string
+		name1         string
+		name2         string
+		minSimilarity float64
+		maxSimilarity float64
+	}{
+		{
+			desc:          "should match identical names ignoring case and punctuation",
+			name1:         "Keens Steakhouse",
+			name2:         "keens steakhouse!",
+			minSimilarity: 1,
+			maxSimilarity: 1,
+		},
+		{
+			desc:          "should ignore leading articles",
+			name1:         "The Empire State Building",
+			name2:         "Empire State Building",
+			minSimilarity: 1,
+			maxSimilarity: 1,
+		},
+		{
+			desc:          "should consider abbreviations similar",
+			name1:         "Empire State Bldg.",
+			name2:         "Empire State Building",
+			minSimilarity: 0.7,
+			maxSimilarity: 0.9,
+		},
+		{
+			desc:          "should not match different places",
+			name1:         "Keens Steakhouse",
+			name2:         "Macy's Herald Square",
+			minSimilarity: 0,
+			maxSimilarity: 0.3,
+		},
+		{
+			desc:          "should not match empty names",
+			name1:         "",
+			name2:         "",
+			minSimilarity: 0,
+			maxSimilarity: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			similarity := nameSimilarity(test.name1, test.name2)
+			tt.AssertTrue(t, similarity >= test.minSimilarity && similarity <= test.maxSimilarity)
+		})
+	}
+}
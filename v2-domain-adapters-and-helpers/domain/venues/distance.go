@@ -0,0 +1,33 @@
+package venues
+
+import (
+	"math"
+	"sort"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/geo"
+)
+
+// sortByDistance recomputes the distance and bearing of the venues from the
+// search point, since each provider computes them differently (or not at all),
+// then removes the venues outside the radius, if any, and sorts the rest
+// from the closest to the farthest.
+func sortByDistance(venues []domain.Venue, from domain.Coordinates, radius int) []domain.Venue {
+	result := make([]domain.Venue, 0, len(venues))
+	for _, v := range venues {
+		distance := geo.Distance(from.Latitude, from.Longitude, v.Location.Latitude, v.Location.Longitude)
+		if radius > 0 && distance > float64(radius) {
+			continue
+		}
+
+		v.Location.Distance = int(math.Round(distance))
+		// Bearings such as 359.6 are rounded to 360, which is the same as 0:
+		v.Location.Bearing = math.Mod(math.Round(geo.Bearing(from.Latitude, from.Longitude, v.Location.Latitude, v.Location.Longitude)), 360)
+		result = append(result, v)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Location.Distance < result[j].Location.Distance
+	})
+	return result
+}
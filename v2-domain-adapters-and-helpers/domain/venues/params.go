@@ -0,0 +1,93 @@
+package venues
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+)
+
+// The limits below are the strictest ones among the supported providers
+const (
+	MaxSearchRadius     = 100000
+	MaxSearchLimit      = 50
+	MaxSearchQueryLen   = 200
+	MaxSearchCategories = 20
+
+	// MaxAreaSearchLimit is higher than MaxSearchLimit since
+	// the area searches are split into several provider searches
+	MaxAreaSearchLimit = 500
+)
+
+// Category IDs can be Foursquare IDs, e.g. "4bf58dd8d48988d1e0931735" or "13065",
+// or OpenStreetMap tags, e.g. "amenity=restaurant":
+var categoryIDRegex = regexp.MustCompile(`^[A-Za-z0-9_.:-]+(=[A-Za-z0-9_.:-]+)?$`)
+
+func validateSearchParams(params domain.VenueSearchParams) error {
+	err := params.Coordinates.Validate()
+	if err != nil {
+		return err
+	}
+
+	if params.Radius < 0 || params.Radius > MaxSearchRadius {
+		return domain.BadRequestErr("invalid-search-radius", map[string]interface{}{
+			"radius":     params.Radius,
+			"max_radius": MaxSearchRadius,
+		})
+	}
+
+	if params.Limit < 0 || params.Limit > MaxSearchLimit {
+		return domain.BadRequestErr("invalid-search-limit", map[string]interface{}{
+			"limit":     params.Limit,
+			"max_limit": MaxSearchLimit,
+		})
+	}
+
+	return validateSearchFilters(params.Query, params.CategoryIDs)
+}
+
+func validateAreaSearchParams(params domain.VenueAreaSearchParams) error {
+	err := params.BoundingBox.Validate()
+	if err != nil {
+		return err
+	}
+
+	if params.Polygon != nil {
+		err = params.Polygon.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	if params.Limit < 0 || params.Limit > MaxAreaSearchLimit {
+		return domain.BadRequestErr("invalid-search-limit", map[string]interface{}{
+			"limit":     params.Limit,
+			"max_limit": MaxAreaSearchLimit,
+		})
+	}
+
+	return validateSearchFilters(params.Query, params.CategoryIDs)
+}
+
+func validateSearchFilters(query string, categoryIDs []string) error {
+	if utf8.RuneCountInString(query) > MaxSearchQueryLen {
+		return domain.BadRequestErr("search-query-too-long", map[string]interface{}{
+			"max_length": MaxSearchQueryLen,
+		})
+	}
+
+	if len(categoryIDs) > MaxSearchCategories {
+		return domain.BadRequestErr("too-many-search-categories", map[string]interface{}{
+			"max_categories": MaxSearchCategories,
+		})
+	}
+	for _, categoryID := range categoryIDs {
+		if !categoryIDRegex.MatchString(categoryID) {
+			return domain.BadRequestErr("invalid-search-category", map[string]interface{}{
+				"category_id": categoryID,
+			})
+		}
+	}
+
+	return nil
+}
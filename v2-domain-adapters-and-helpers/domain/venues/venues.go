@@ -2,78 +2,349 @@ package venues
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
-	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue"
 	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/geo"
 )
 
 type Service struct {
 	logger log.Provider
-	rest   rest.Provider
+	venues venue.Provider
 	cache  cache.Provider
+	config Config
+}
+
+// Config describes the optional configurations of the service
+type Config struct {
+	// ProviderName is the name of the main provider used on the
+	// logs and warnings. Defaults to "primary".
+	ProviderName string
+
+	// ExtraProviders are searched concurrently with the main provider,
+	// the results are merged and the duplicated venues removed.
+	//
+	// The IDs of the venues found by the extra providers are prefixed with
+	// the name of the provider, e.g. "overpass:node-123", so their details
+	// are fetched from the same provider. The IDs of the main provider
+	// are never prefixed.
+	ExtraProviders []NamedProvider
+
+	// Two venues are considered the same place if they are at most
+	// DedupMaxDistance meters apart and the similarity of their names,
+	// between 0 and 1, is at least DedupMinSimilarity.
+	//
+	// Defaults to 50 meters and 0.7 respectively.
+	DedupMaxDistance   float64
+	DedupMinSimilarity float64
+
+	// SearchCachePrecision is the number of characters of the geohash cells
+	// used for caching the searches, e.g. 6 for cells of about 1.2km x 0.6km,
+	// it must be between MinSearchCachePrecision and MaxSearchCachePrecision.
+	//
+	// All searches with a radius inside a cell share the same provider request,
+	// centered on the cell, and the results are then filtered by the distance to
+	// the actual point. The cells with more venues than the limit of the search
+	// are remembered and searched from the actual point instead.
+	// Searches are not cached if it is 0.
+	SearchCachePrecision int
+
+	// The area searches are split into a grid of provider searches
+	// with cells of at most AreaCellSize x AreaCellSize meters,
+	// areas needing more than MaxAreaQueries cells are rejected.
+	// Defaults to cells of 2000 meters and 9 queries.
+	AreaCellSize   int
+	MaxAreaQueries int
 
-	baseURL  string
-	clientID string
-	secret   string
+	// MaxAreaConcurrency is the maximum number of cells of
+	// an area searched at the same time. Defaults to 3.
+	MaxAreaConcurrency int
+}
+
+// The supported range for the SearchCachePrecision, the cells of lower
+// precisions are too large to be covered by a single provider search.
+const (
+	MinSearchCachePrecision = 5
+	MaxSearchCachePrecision = 9
+)
+
+// NamedProvider is a venue.Provider identified by a name,
+// the name must not contain the ":" character.
+type NamedProvider struct {
+	Name     string
+	Provider venue.Provider
 }
 
 func NewService(
 	logger log.Provider,
-	rest rest.Provider,
+	venues venue.Provider,
 	cache cache.Provider,
-	baseURL string,
-	clientID string,
-	secret string,
+	config Config,
 ) Service {
+	if config.ProviderName == "" {
+		config.ProviderName = "primary"
+	}
+	if config.DedupMaxDistance <= 0 {
+		config.DedupMaxDistance = 50
+	}
+	if config.DedupMinSimilarity <= 0 {
+		config.DedupMinSimilarity = 0.7
+	}
+	if config.AreaCellSize <= 0 {
+		config.AreaCellSize = 2000
+	}
+	if config.MaxAreaQueries <= 0 {
+		config.MaxAreaQueries = 9
+	}
+	if config.MaxAreaConcurrency <= 0 {
+		config.MaxAreaConcurrency = 3
+	}
+
 	return Service{
-		logger:   logger,
-		cache:    cache,
-		rest:     rest,
-		baseURL:  baseURL,
-		clientID: clientID,
-		secret:   secret,
+		logger: logger,
+		venues: venues,
+		cache:  cache,
+		config: config,
 	}
 }
 
-func (s Service) GetVenues(ctx context.Context, latitude string, longitude string) ([]domain.Venue, error) {
-	url := fmt.Sprintf("%s/venues/search?client_id=%s&client_secret=%s&v=20210514&ll=%s,%s", s.baseURL, s.clientID, s.secret, latitude, longitude)
-	resp, err := s.rest.Get(ctx, url, rest.RequestData{})
+// HasExtraProviders tells if the searches merge the results of several providers
+func (s Service) HasExtraProviders() bool {
+	return len(s.config.ExtraProviders) > 0
+}
+
+// GetVenues searches the venues on all the providers,
+// if some of them fail the venues found by the others are
+// returned with one warning for each failed provider.
+func (s Service) GetVenues(ctx context.Context, params domain.VenueSearchParams) (domain.VenueSearch, error) {
+	err := validateSearchParams(params)
+	if err != nil {
+		return domain.VenueSearch{}, err
+	}
+
+	search, err := s.search(ctx, params)
 	if err != nil {
-		s.logger.Error(ctx, "error-retrieving-venues-from-foursquare-by-coordinates", log.Body{
-			"latitude":  latitude,
-			"longitude": longitude,
-			"payload":   string(resp.Body),
+		return domain.VenueSearch{}, err
+	}
+
+	search.Venues = sortByDistance(search.Venues, params.Coordinates, params.Radius)
+
+	// Each provider respects the limit, but not the merged results:
+	if params.Limit > 0 && len(search.Venues) > params.Limit {
+		search.Venues = search.Venues[:params.Limit]
+	}
+	return search, nil
+}
+
+// search searches the venues on all providers, using the cache when it is enabled
+func (s Service) search(ctx context.Context, params domain.VenueSearchParams) (domain.VenueSearch, error) {
+	if s.isCached(params) {
+		return s.searchCell(ctx, params)
+	}
+	return s.searchProviders(ctx, params)
+}
+
+func (s Service) isCached(params domain.VenueSearchParams) bool {
+	// The searches of the cells always have a radius, so the searches without
+	// one are sent as they are, e.g. Foursquare ranks them differently.
+	//
+	// Opening hours change during the day, so these searches are never cached:
+	return s.config.SearchCachePrecision > 0 && params.Radius > 0 && !params.OpenNow
+}
+
+// searchCell searches the venues of the whole geohash cell containing the
+// search point, using the cache for the searches already made on the cell.
+func (s Service) searchCell(ctx context.Context, params domain.VenueSearchParams) (domain.VenueSearch, error) {
+	cellHash := geo.Geohash(params.Coordinates.Latitude, params.Coordinates.Longitude, s.config.SearchCachePrecision)
+	cell, err := geo.DecodeGeohash(cellHash)
+	if err != nil {
+		return domain.VenueSearch{}, domain.InternalErr("unable-to-decode-geohash", map[string]interface{}{
+			"geohash": cellHash,
+			"error":   err.Error(),
 		})
-		return nil, domain.InternalErr("error-retrieving-venues-from-foursquare", map[string]interface{}{
-			"latitude":  latitude,
-			"longitude": longitude,
+	}
+
+	// Searching from the center with the radius increased by the size of the cell
+	// covers the area of any search inside the cell. Searches with no limit
+	// use the highest one, so the cell result is complete more often:
+	centerLat, centerLng := cell.Center()
+	cellParams := params
+	cellParams.Coordinates = domain.Coordinates{Latitude: centerLat, Longitude: centerLng}
+	cellParams.Radius = params.Radius + int(math.Ceil(cell.Radius()))
+	if cellParams.Limit == 0 {
+		cellParams.Limit = MaxSearchLimit
+	}
+
+	// The providers would not cover the whole cell:
+	if cellParams.Radius > MaxSearchRadius {
+		return s.searchProviders(ctx, params)
+	}
+
+	cacheKey := searchCacheKey(cellHash, cellParams)
+
+	var cachedSearch domain.VenueSearch
+	err = s.cache.Get(ctx, cacheKey, &cachedSearch)
+	if err == nil {
+		s.logger.Debug(ctx, "fetching-venue-search-from-cache", log.Body{
+			"cache_key": cacheKey,
 		})
+		return cachedSearch, nil
 	}
 
-	var respBody struct {
-		Meta struct {
-			Code      int    `json:"code"`
-			RequestID string `json:"requestId"`
-		}
-		Response struct {
-			Venues []domain.Venue `json:"venues"`
-		} `json:"response"`
+	// The cells with more venues than the limit can't be cached,
+	// so we remember them for searching the actual point directly:
+	denseCellKey := "dense-" + cacheKey
+	var isDense bool
+	err = s.cache.Get(ctx, denseCellKey, &isDense)
+	if err == nil && isDense {
+		return s.searchProviders(ctx, params)
 	}
-	err = json.Unmarshal(resp.Body, &respBody)
+
+	search, err := s.searchProviders(ctx, cellParams)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing foursquare venues as JSON: %s", err)
+		return domain.VenueSearch{}, err
+	}
+
+	// If the providers hit the limit there might be venues closer to the
+	// actual point that were left out, so the cell search can't be used,
+	// this second search only happens on the first search of each dense cell:
+	if len(search.Venues) >= cellParams.Limit {
+		err = s.cache.Set(ctx, denseCellKey, true)
+		if err != nil {
+			s.logger.Warn(ctx, "unable-to-cache-dense-venue-search-cell", log.Body{
+				"cache_key": denseCellKey,
+				"error":     err.Error(),
+			})
+		}
+		return s.searchProviders(ctx, params)
 	}
 
-	return respBody.Response.Venues, nil
+	// Partial results are not cached so the failed providers are tried again on the next search:
+	if len(search.Warnings) == 0 {
+		err = s.cache.Set(ctx, cacheKey, search)
+		if err != nil {
+			s.logger.Warn(ctx, "unable-to-cache-venue-search", log.Body{
+				"cache_key": cacheKey,
+				"error":     err.Error(),
+			})
+		}
+	}
+
+	return search, nil
+}
+
+// searchCacheKey builds a key such as "venue-search:dr5ru6?categories=13065&limit=50&query=pizza&radius=1550"
+func searchCacheKey(cellHash string, params domain.VenueSearchParams) string {
+	categoryIDs := append([]string{}, params.CategoryIDs...)
+	sort.Strings(categoryIDs)
+
+	query := url.Values{
+		"radius": {strconv.Itoa(params.Radius)},
+		"limit":  {strconv.Itoa(params.Limit)},
+	}
+	if params.Query != "" {
+		query.Set("query", params.Query)
+	}
+	if len(categoryIDs) > 0 {
+		query.Set("categories", strings.Join(categoryIDs, ","))
+	}
+
+	return "venue-search:" + cellHash + "?" + query.Encode()
 }
 
-func (s Service) GetVenue(ctx context.Context, venueID string) ([]byte, error) {
-	var cachedVenue []byte
-	err := s.cache.Get(ctx, venueID, &cachedVenue)
+// searchProviders searches the venues on all the providers,
+// if some of them fail the venues found by the others are
+// returned with one warning for each failed provider.
+func (s Service) searchProviders(ctx context.Context, params domain.VenueSearchParams) (domain.VenueSearch, error) {
+	providers := append([]NamedProvider{{
+		Name:     s.config.ProviderName,
+		Provider: s.venues,
+	}}, s.config.ExtraProviders...)
+
+	type result struct {
+		venues []domain.Venue
+		err    error
+	}
+	results := make([]result, len(providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider NamedProvider) {
+			defer wg.Done()
+			venues, err := provider.Provider.GetVenues(ctx, params)
+			results[i] = result{venues: venues, err: err}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	var search domain.VenueSearch
+	var firstErr error
+	var succeeded int
+	for i, r := range results {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+
+			s.logger.Warn(ctx, "venue-provider-failed", log.Body{
+				"provider":    providers[i].Name,
+				"coordinates": params.Coordinates.String(),
+				"error":       r.err.Error(),
+			})
+			search.Warnings = append(search.Warnings, domain.SearchWarning{
+				Provider: providers[i].Name,
+				Code:     domain.AsDomainErr(r.err).Code,
+				Title:    "venue-provider-failed",
+			})
+			continue
+		}
+
+		succeeded++
+		venues := r.venues
+		if i > 0 {
+			venues = withProviderPrefix(providers[i].Name, venues)
+		}
+		search.Venues = mergeVenues(search.Venues, venues, s.config.DedupMaxDistance, s.config.DedupMinSimilarity)
+	}
+
+	if succeeded > 0 {
+		return search, nil
+	}
+
+	// These errors mean the upstream is fine but we should try again later,
+	// or that the input was rejected by the provider:
+	code := domain.AsDomainErr(firstErr).Code
+	if code == "UnavailableErr" || code == "RateLimitedErr" || code == "BadRequestErr" {
+		return domain.VenueSearch{}, clientErr(firstErr, map[string]interface{}{
+			"coordinates": params.Coordinates.String(),
+		})
+	}
+
+	s.logger.Error(ctx, "error-retrieving-venues-by-coordinates", log.Body{
+		"coordinates": params.Coordinates.String(),
+		"error":       firstErr.Error(),
+	})
+	return domain.VenueSearch{}, domain.InternalErr("error-retrieving-venues-from-provider", map[string]interface{}{
+		"coordinates": params.Coordinates.String(),
+	})
+}
+
+func (s Service) GetVenue(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+	// The prefix keeps these entries apart from the ones
+	// cached when the details were stored as raw bytes:
+	cacheKey := "venue-details:" + venueID
+
+	var cachedVenue domain.VenueDetails
+	err := s.cache.Get(ctx, cacheKey, &cachedVenue)
 	if err == nil {
 		// Log IDs, not payloads whenever possible, except when errors happen, then log everything.
 		s.logger.Debug(ctx, "fetching-venue-from-cache", log.Body{
@@ -82,20 +353,72 @@ func (s Service) GetVenue(ctx context.Context, venueID string) ([]byte, error) {
 		return cachedVenue, nil
 	}
 
-	url := fmt.Sprintf("%s/venues/%s?client_id=%s&client_secret=%s&v=20210514", s.baseURL, venueID, s.clientID, s.secret)
-	resp, err := s.rest.Get(ctx, url, rest.RequestData{})
+	provider, providerVenueID := s.providerOf(venueID)
+	venue, err := provider.GetVenue(ctx, providerVenueID)
 	if err != nil {
-		s.logger.Error(ctx, "error-fetching-venue-by-latitude-from-foursquare", log.Body{
+		s.logger.Error(ctx, "error-fetching-venue-from-provider", log.Body{
 			"venue_id": venueID,
 			"error":    err.Error(),
-			"payload":  string(resp.Body),
 		})
-		return nil, err
+		return domain.VenueDetails{}, clientErr(err, map[string]interface{}{
+			"venue_id": venueID,
+		})
 	}
 
+	// The details should have the same ID returned on the searches:
+	venue.ID = venueID
+
 	s.logger.Debug(ctx, "adding-venue-to-cache", log.Body{
 		"venue_id": venueID,
 	})
-	err = s.cache.Set(ctx, venueID, resp.Body)
-	return resp.Body, err
+	err = s.cache.Set(ctx, cacheKey, venue)
+	return venue, err
+}
+
+// withProviderPrefix returns a copy of the venues with
+// their IDs prefixed by the name of the provider
+func withProviderPrefix(providerName string, venues []domain.Venue) []domain.Venue {
+	prefixed := make([]domain.Venue, 0, len(venues))
+	for _, v := range venues {
+		v.ID = providerName + ":" + v.ID
+		prefixed = append(prefixed, v)
+	}
+	return prefixed
+}
+
+// providerOf returns the provider that found the input venue along with
+// the ID of the venue on that provider, IDs with no prefix of an extra
+// provider belong to the main provider.
+func (s Service) providerOf(venueID string) (venue.Provider, string) {
+	providerName, providerVenueID, found := strings.Cut(venueID, ":")
+	if !found {
+		return s.venues, venueID
+	}
+
+	for _, p := range s.config.ExtraProviders {
+		if p.Name == providerName {
+			return p.Provider, providerVenueID
+		}
+	}
+	return s.venues, venueID
+}
+
+// clientErr rebuilds the errors from the providers that are returned to our
+// clients, keeping their code and title but replacing their data, which might
+// describe the upstream services, e.g. their URLs and payloads, with the input one.
+//
+// The errors not meant for our clients are converted to internal errors,
+// so the original error should be logged before calling it.
+func clientErr(err error, data map[string]interface{}) error {
+	domainErr := domain.AsDomainErr(err)
+	switch domainErr.Code {
+	case "NotFoundErr", "UnavailableErr", "RateLimitedErr", "BadRequestErr":
+		return domain.DomainErr{
+			Code:  domainErr.Code,
+			Title: domainErr.Title,
+			Data:  data,
+		}
+	default:
+		return domain.InternalErr("error-fetching-venue-from-provider", data)
+	}
 }
@@ -0,0 +1,576 @@
+package venues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/cache/memorycache"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/log"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/rest"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/adapters/venue"
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/domain"
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestGetVenues(t *testing.T) {
+	ctx := context.Background()
+
+	empireState := domain.Venue{
+		ID:   "fakeFoursquareID",
+		Name: "Empire State Building",
+		Location: domain.Location{
+			Latitude:  40.748441,
+			Longitude: -73.985664,
+		},
+	}
+
+	// The distance and bearing are always computed from the search point:
+	searchedEmpireState := empireState
+	searchedEmpireState.Location.Distance = 5
+	searchedEmpireState.Location.Bearing = 34
+
+	t.Run("should return the venues of the provider", func(t *testing.T) {
+		var paramsArg domain.VenueSearchParams
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				paramsArg = params
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{}, Config{})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, search, domain.VenueSearch{
+			Venues: []domain.Venue{searchedEmpireState},
+		})
+		tt.AssertEqual(t, paramsArg, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+	})
+
+	t.Run("should hide unexpected errors from the provider", func(t *testing.T) {
+		svc := NewService(log.Mock{
+			ErrorFn: func(ctx context.Context, title string, valueMaps ...log.Body) {},
+		}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return nil, errors.New("fakeErrMsg")
+			},
+		}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "InternalErr")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Title, "error-retrieving-venues-from-provider")
+	})
+
+	t.Run("should pass through errors that are meaningful for the client", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return nil, domain.UnavailableErr("upstream-service-unavailable", nil)
+			},
+		}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "UnavailableErr")
+	})
+
+	t.Run("should merge the venues of all providers removing duplicates", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{}, Config{
+			ProviderName: "foursquare",
+			ExtraProviders: []NamedProvider{{
+				Name: "overpass",
+				Provider: venue.Mock{
+					GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+						return []domain.Venue{
+							{
+								ID:   "way-34633854",
+								Name: "The Empire State Building",
+								Location: domain.Location{
+									// About 20 meters from the foursquare coordinates:
+									Latitude:  40.748600,
+									Longitude: -73.985550,
+								},
+							},
+							{
+								ID:   "node-2709306673",
+								Name: "Keens Steakhouse",
+								Location: domain.Location{
+									Latitude:  40.750823,
+									Longitude: -73.988186,
+								},
+							},
+						}, nil
+					},
+				},
+			}},
+		})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 2)
+		tt.AssertEqual(t, search.Venues[0], searchedEmpireState)
+		tt.AssertEqual(t, search.Venues[1].ID, "overpass:node-2709306673")
+		tt.AssertEqual(t, len(search.Warnings), 0)
+	})
+
+	t.Run("should return the venues that succeeded with warnings for the failed providers", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return nil, domain.UnavailableErr("upstream-service-unavailable", nil)
+			},
+		}, cache.Mock{}, Config{
+			ProviderName: "foursquare",
+			ExtraProviders: []NamedProvider{{
+				Name: "overpass",
+				Provider: venue.Mock{
+					GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+						return []domain.Venue{empireState}, nil
+					},
+				},
+			}},
+		})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+
+		// The venues of the extra providers have their IDs prefixed:
+		overpassEmpireState := searchedEmpireState
+		overpassEmpireState.ID = "overpass:" + empireState.ID
+		tt.AssertEqual(t, search, domain.VenueSearch{
+			Venues: []domain.Venue{overpassEmpireState},
+			Warnings: []domain.SearchWarning{{
+				Provider: "foursquare",
+				Code:     "UnavailableErr",
+				Title:    "venue-provider-failed",
+			}},
+		})
+	})
+
+	t.Run("should sort the venues by distance and remove the ones outside the radius", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return []domain.Venue{
+					{ID: "fakeFarID", Location: domain.Location{Latitude: 40.7584, Longitude: -73.9857, Distance: 1}},
+					{ID: "fakeMiddleID", Location: domain.Location{Latitude: 40.7484, Longitude: -73.9817}},
+					{ID: "fakeCloseID", Location: domain.Location{Latitude: 40.7474, Longitude: -73.9857}},
+				}, nil
+			},
+		}, cache.Mock{}, Config{})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      1000,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 2)
+
+		tt.AssertEqual(t, search.Venues[0].ID, "fakeCloseID")
+		tt.AssertEqual(t, search.Venues[0].Location.Distance, 111)
+		tt.AssertEqual(t, search.Venues[0].Location.Bearing, 180.0)
+
+		tt.AssertEqual(t, search.Venues[1].ID, "fakeMiddleID")
+		tt.AssertEqual(t, search.Venues[1].Location.Distance, 337)
+		tt.AssertEqual(t, search.Venues[1].Location.Bearing, 90.0)
+	})
+
+	t.Run("should report bearings that round up to 360 as 0", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return []domain.Venue{
+					// Slightly west of north, i.e. a bearing of about 359.7:
+					{ID: "fakeNorthID", Location: domain.Location{Latitude: 40.7584, Longitude: -73.98577}},
+				}, nil
+			},
+		}, cache.Mock{}, Config{})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, search.Venues[0].Location.Bearing, 0.0)
+	})
+
+	t.Run("should fail if all providers fail", func(t *testing.T) {
+		failingProvider := venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				return nil, domain.RateLimitedErr("upstream-rate-limited", nil)
+			},
+		}
+		svc := NewService(log.Mock{}, failingProvider, cache.Mock{}, Config{
+			ExtraProviders: []NamedProvider{{
+				Name:     "overpass",
+				Provider: failingProvider,
+			}},
+		})
+
+		_, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+		})
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "RateLimitedErr")
+	})
+
+	t.Run("should share the cached search among the points of the same geohash cell", func(t *testing.T) {
+		north := domain.Venue{
+			ID:   "fakeNorthID",
+			Name: "fakeNorthName",
+			Location: domain.Location{
+				Latitude:  40.753,
+				Longitude: -73.9857,
+			},
+		}
+
+		var paramsArgs []domain.VenueSearchParams
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				paramsArgs = append(paramsArgs, params)
+				return []domain.Venue{empireState, north}, nil
+			},
+		}, memorycache.New(time.Minute, time.Minute), Config{
+			SearchCachePrecision: 6,
+		})
+
+		// Both points are on the cell "dr5ru6", about 400m apart:
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      300,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 1)
+		tt.AssertEqual(t, search.Venues[0].ID, "fakeFoursquareID")
+
+		search, err = svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.752, Longitude: -73.9857},
+			Radius:      300,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 1)
+		tt.AssertEqual(t, search.Venues[0].ID, "fakeNorthID")
+		tt.AssertEqual(t, search.Venues[0].Location.Distance, 111)
+
+		// The provider is only called once, from the center of the cell:
+		tt.AssertEqual(t, len(paramsArgs), 1)
+		tt.AssertEqual(t, paramsArgs[0].Coordinates.String(), "40.751038,-73.987427")
+		tt.AssertEqual(t, paramsArgs[0].Radius, 300+555)
+		tt.AssertEqual(t, paramsArgs[0].Limit, MaxSearchLimit)
+	})
+
+	t.Run("should not cache open now searches nor searches with warnings", func(t *testing.T) {
+		var setCalls int
+		var providerCalls int
+		svc := NewService(log.Mock{
+			WarnFn: func(ctx context.Context, title string, valueMaps ...log.Body) {},
+		}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				providerCalls++
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{
+			SetFn: func(ctx context.Context, key string, record interface{}) error {
+				setCalls++
+				return nil
+			},
+		}, Config{
+			SearchCachePrecision: 6,
+			ExtraProviders: []NamedProvider{{
+				Name: "overpass",
+				Provider: venue.Mock{
+					GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+						if params.OpenNow {
+							return nil, nil
+						}
+						return nil, domain.UnavailableErr("upstream-service-unavailable", nil)
+					},
+				},
+			}},
+		})
+
+		search, err := svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			OpenNow:     true,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Venues), 1)
+
+		search, err = svc.GetVenues(ctx, domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      300,
+		})
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(search.Warnings), 1)
+
+		tt.AssertEqual(t, providerCalls, 2)
+		tt.AssertEqual(t, setCalls, 0)
+	})
+
+	t.Run("should send the searches without radius as they are", func(t *testing.T) {
+		var paramsArgs []domain.VenueSearchParams
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				paramsArgs = append(paramsArgs, params)
+				return []domain.Venue{empireState}, nil
+			},
+		}, cache.Mock{
+			SetFn: func(ctx context.Context, key string, record interface{}) error {
+				t.Fatalf("unexpected call to cache.Set with key %s", key)
+				return nil
+			},
+		}, Config{
+			SearchCachePrecision: 6,
+		})
+
+		params := domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Limit:       5,
+		}
+		_, err := svc.GetVenues(ctx, params)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, paramsArgs, []domain.VenueSearchParams{params})
+	})
+
+	t.Run("should remember the cells with more venues than the limit", func(t *testing.T) {
+		var paramsArgs []domain.VenueSearchParams
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+				paramsArgs = append(paramsArgs, params)
+
+				venues := []domain.Venue{}
+				for i := 0; i < params.Limit; i++ {
+					venues = append(venues, domain.Venue{
+						ID:       fmt.Sprintf("fakeID%d", i),
+						Name:     fmt.Sprintf("fakeName%d", i),
+						Location: domain.Location{Latitude: 40.7484 + float64(i)*0.0001, Longitude: -73.9857},
+					})
+				}
+				return venues, nil
+			},
+		}, memorycache.New(time.Minute, time.Minute), Config{
+			SearchCachePrecision: 6,
+		})
+
+		params := domain.VenueSearchParams{
+			Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+			Radius:      300,
+			Limit:       10,
+		}
+		_, err := svc.GetVenues(ctx, params)
+		tt.AssertNoErr(t, err)
+
+		// The cell search uses the limit of the caller and, since it
+		// hit the limit, the actual point is searched as well:
+		tt.AssertEqual(t, len(paramsArgs), 2)
+		tt.AssertEqual(t, paramsArgs[0].Limit, 10)
+		tt.AssertEqual(t, paramsArgs[1], params)
+
+		// The next searches on the same cell go straight to the actual point:
+		params.Coordinates = domain.Coordinates{Latitude: 40.752, Longitude: -73.9857}
+		_, err = svc.GetVenues(ctx, params)
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, len(paramsArgs), 3)
+		tt.AssertEqual(t, paramsArgs[2], params)
+	})
+}
+
+func TestSearchCacheKey(t *testing.T) {
+	t.Run("should not depend on the order of the categories", func(t *testing.T) {
+		key1 := searchCacheKey("dr5ru6", domain.VenueSearchParams{
+			Radius:      1555,
+			Query:       "pizza & pasta",
+			CategoryIDs: []string{"13065", "13064"},
+		})
+		key2 := searchCacheKey("dr5ru6", domain.VenueSearchParams{
+			Radius:      1555,
+			Query:       "pizza & pasta",
+			CategoryIDs: []string{"13064", "13065"},
+		})
+		tt.AssertEqual(t, key1, "venue-search:dr5ru6?categories=13064%2C13065&limit=0&query=pizza+%26+pasta&radius=1555")
+		tt.AssertEqual(t, key2, key1)
+	})
+}
+
+func TestValidateSearchParams(t *testing.T) {
+	tests := []struct {
+		desc          string
+		params        domain.VenueSearchParams
+		expectedTitle string
+	}{
+		{
+			desc: "should accept all valid params",
+			params: domain.VenueSearchParams{
+				Coordinates: domain.Coordinates{Latitude: 40.7484, Longitude: -73.9857},
+				Radius:      1000,
+				Query:       "pizza",
+				CategoryIDs: []string{"4bf58dd8d48988d1e0931735", "13065", "amenity=restaurant"},
+				Limit:       50,
+				OpenNow:     true,
+			},
+		},
+		{
+			desc:          "should reject coordinates out of range",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 91, Longitude: 1}},
+			expectedTitle: "invalid-coordinates",
+		},
+		{
+			desc:          "should reject a negative radius",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 1, Longitude: 1}, Radius: -1},
+			expectedTitle: "invalid-search-radius",
+		},
+		{
+			desc:          "should reject a radius above the max",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 1, Longitude: 1}, Radius: MaxSearchRadius + 1},
+			expectedTitle: "invalid-search-radius",
+		},
+		{
+			desc:          "should reject a limit above the max",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 1, Longitude: 1}, Limit: 51},
+			expectedTitle: "invalid-search-limit",
+		},
+		{
+			desc:          "should reject long queries",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 1, Longitude: 1}, Query: strings.Repeat("a", 201)},
+			expectedTitle: "search-query-too-long",
+		},
+		{
+			desc:          "should reject invalid category IDs",
+			params:        domain.VenueSearchParams{Coordinates: domain.Coordinates{Latitude: 1, Longitude: 1}, CategoryIDs: []string{`amenity"];out;`}},
+			expectedTitle: "invalid-search-category",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var called bool
+			svc := NewService(log.Mock{}, venue.Mock{
+				GetVenuesFn: func(ctx context.Context, params domain.VenueSearchParams) ([]domain.Venue, error) {
+					called = true
+					return []domain.Venue{}, nil
+				},
+			}, cache.Mock{}, Config{})
+
+			_, err := svc.GetVenues(context.Background(), test.params)
+			if test.expectedTitle == "" {
+				tt.AssertNoErr(t, err)
+				tt.AssertTrue(t, called)
+				return
+			}
+
+			tt.AssertEqual(t, domain.AsDomainErr(err).Code, "BadRequestErr")
+			tt.AssertEqual(t, domain.AsDomainErr(err).Title, test.expectedTitle)
+			tt.AssertFalse(t, called)
+		})
+	}
+}
+
+func TestGetVenue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should fetch the venue and save it on the cache", func(t *testing.T) {
+		var cachedKey string
+		var cachedRecord interface{}
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenueFn: func(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+				return domain.VenueDetails{ID: venueID, Name: "fakeName"}, nil
+			},
+		}, cache.Mock{
+			SetFn: func(ctx context.Context, key string, record interface{}) error {
+				cachedKey = key
+				cachedRecord = record
+				return nil
+			},
+		}, Config{})
+
+		venue, err := svc.GetVenue(ctx, "fakeVenueID")
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, venue, domain.VenueDetails{ID: "fakeVenueID", Name: "fakeName"})
+
+		tt.AssertEqual(t, cachedKey, "venue-details:fakeVenueID")
+		tt.AssertEqual(t, cachedRecord, venue)
+	})
+
+	t.Run("should return the cached venue without calling the provider", func(t *testing.T) {
+		svc := NewService(log.Mock{}, venue.Mock{}, cache.Mock{
+			GetFn: func(ctx context.Context, key string, record interface{}) error {
+				*record.(*domain.VenueDetails) = domain.VenueDetails{ID: "fakeVenueID", Name: "fakeCachedName"}
+				return nil
+			},
+		}, Config{})
+
+		venue, err := svc.GetVenue(ctx, "fakeVenueID")
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, venue, domain.VenueDetails{ID: "fakeVenueID", Name: "fakeCachedName"})
+	})
+
+	t.Run("should fetch the venues found by extra providers from the same provider", func(t *testing.T) {
+		var requestedID string
+		svc := NewService(log.Mock{}, venue.Mock{
+			GetVenueFn: func(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+				return domain.VenueDetails{}, errors.New("main provider should not be called")
+			},
+		}, cache.Mock{}, Config{
+			ProviderName: "foursquare",
+			ExtraProviders: []NamedProvider{{
+				Name: "overpass",
+				Provider: venue.Mock{
+					GetVenueFn: func(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+						requestedID = venueID
+						return domain.VenueDetails{ID: venueID, Name: "Keens Steakhouse"}, nil
+					},
+				},
+			}},
+		})
+
+		venue, err := svc.GetVenue(ctx, "overpass:node-2709306673")
+		tt.AssertNoErr(t, err)
+		tt.AssertEqual(t, requestedID, "node-2709306673")
+		tt.AssertEqual(t, venue, domain.VenueDetails{ID: "overpass:node-2709306673", Name: "Keens Steakhouse"})
+	})
+
+	t.Run("should report errors from the provider", func(t *testing.T) {
+		svc := NewService(log.Mock{
+			ErrorFn: func(ctx context.Context, title string, valueMaps ...log.Body) {},
+		}, venue.Mock{
+			GetVenueFn: func(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+				return domain.VenueDetails{}, domain.NotFoundErr("venue-not-found", nil)
+			},
+		}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenue(ctx, "fakeVenueID")
+		tt.AssertEqual(t, domain.AsDomainErr(err).Code, "NotFoundErr")
+	})
+
+	t.Run("should not expose the upstream details to our clients", func(t *testing.T) {
+		var loggedErr string
+		svc := NewService(log.Mock{
+			ErrorFn: func(ctx context.Context, title string, valueMaps ...log.Body) {
+				loggedErr = valueMaps[0]["error"].(string)
+			},
+		}, venue.Mock{
+			GetVenueFn: func(ctx context.Context, venueID string) (domain.VenueDetails, error) {
+				return domain.VenueDetails{}, rest.NewStatusError("GET", "https://fake.host/v2/venues/fakeVenueID", 503, []byte("fakeUpstreamPayload"))
+			},
+		}, cache.Mock{}, Config{})
+
+		_, err := svc.GetVenue(ctx, "fakeVenueID")
+		tt.AssertEqual(t, domain.AsDomainErr(err), domain.UnavailableErr("upstream-service-unavailable", map[string]interface{}{
+			"venue_id": "fakeVenueID",
+		}))
+		tt.AssertContains(t, loggedErr, "https://fake.host/v2/venues/fakeVenueID", "fakeUpstreamPayload")
+	})
+}
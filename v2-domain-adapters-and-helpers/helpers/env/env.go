@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 func GetString(key string, defaultValue string) string {
@@ -66,3 +67,20 @@ func MustGetFloat(key string) float64 {
 	}
 	return v
 }
+
+// GetDuration parses durations in the format accepted by time.ParseDuration, e.g. "1m30s",
+// it panics if the variable is set but is not a valid duration, e.g. "30" without the unit.
+func GetDuration(key string, defaultValue time.Duration) time.Duration {
+	rawValue := os.Getenv(key)
+	if rawValue == "" {
+		return defaultValue
+	}
+
+	v, err := time.ParseDuration(rawValue)
+	if err != nil {
+		panic(
+			fmt.Sprintf("can't start program: env variable '%s' is not a valid duration: %s", key, err),
+		)
+	}
+	return v
+}
@@ -0,0 +1,389 @@
+package fakefoursquare
+
+// This package is a helper package:
+// (1) it is very simple
+// (2) it only depends on stdlib and on other helpers
+//
+// It implements a fake version of the Foursquare v2 API serving
+// the seeded venues, so the API can run locally and on the tests
+// without real Foursquare credentials.
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/geo"
+)
+
+//go:embed fixtures/venues.json
+var fixtures embed.FS
+
+// Venue is a seeded venue, Fields holds the venue object
+// exactly as it is returned by the details endpoint.
+type Venue struct {
+	ID        string
+	Name      string
+	Latitude  float64
+	Longitude float64
+
+	Fields map[string]interface{}
+}
+
+// UnmarshalJSON parses a venue object in the format of the Foursquare v2 API
+func (v *Venue) UnmarshalJSON(rawJSON []byte) error {
+	var parsed struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Location struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		} `json:"location"`
+	}
+	err := json.Unmarshal(rawJSON, &parsed)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	err = json.Unmarshal(rawJSON, &fields)
+	if err != nil {
+		return err
+	}
+
+	*v = Venue{
+		ID:        parsed.ID,
+		Name:      parsed.Name,
+		Latitude:  parsed.Location.Lat,
+		Longitude: parsed.Location.Lng,
+		Fields:    fields,
+	}
+	return nil
+}
+
+// NewVenue builds a venue with the minimum fields required by the API
+func NewVenue(id string, name string, latitude float64, longitude float64) Venue {
+	return Venue{
+		ID:        id,
+		Name:      name,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Fields: map[string]interface{}{
+			"id":   id,
+			"name": name,
+			"location": map[string]interface{}{
+				"lat": latitude,
+				"lng": longitude,
+			},
+		},
+	}
+}
+
+// DefaultVenues returns the venues seeded by default,
+// all of them around the Empire State Building in New York.
+func DefaultVenues() []Venue {
+	rawJSON, err := fixtures.ReadFile("fixtures/venues.json")
+	if err != nil {
+		panic(fmt.Sprintf("fakefoursquare: unable to read the embedded fixtures: %s", err))
+	}
+
+	venues, err := ParseVenues(rawJSON)
+	if err != nil {
+		panic(fmt.Sprintf("fakefoursquare: unable to parse the embedded fixtures: %s", err))
+	}
+	return venues
+}
+
+// ParseVenues parses a JSON list of venues in the format of the Foursquare v2 API
+func ParseVenues(rawJSON []byte) ([]Venue, error) {
+	var venues []Venue
+	err := json.Unmarshal(rawJSON, &venues)
+	return venues, err
+}
+
+// Config describes the behavior of the fake server
+type Config struct {
+	// Venues defaults to DefaultVenues()
+	Venues []Venue
+
+	// ClientID and ClientSecret are only validated if set
+	ClientID     string
+	ClientSecret string
+
+	// Latency is added to all responses
+	Latency time.Duration
+
+	// ErrorRate is the fraction of the requests, between 0 and 1,
+	// that fail with the ErrorStatus, which defaults to 500.
+	ErrorRate   float64
+	ErrorStatus int
+}
+
+// Server is an http.Handler that serves a fake Foursquare v2 API,
+// the paths can be used with or without the "/v2" prefix:
+//
+//   - GET /venues/search?ll=<lat>,<lng>&radius=<meters>&limit=<n>&query=<name>
+//   - GET /venues/{id}
+type Server struct {
+	mutex   sync.Mutex
+	initial Config
+	config  Config
+	rand    *rand.Rand
+
+	// failures lists the status codes of the next responses
+	failures []int
+}
+
+// New instantiates a new fake server
+func New(config Config) *Server {
+	if config.Venues == nil {
+		config.Venues = DefaultVenues()
+	}
+	if config.ErrorStatus == 0 {
+		config.ErrorStatus = http.StatusInternalServerError
+	}
+
+	return &Server{
+		initial: config,
+		config:  config,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// FailNext makes the next n requests fail with the input status code
+func (s *Server) FailNext(n int, statusCode int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, statusCode)
+	}
+}
+
+// SetLatency changes the latency added to all responses
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.config.Latency = latency
+}
+
+// SetVenues replaces the seeded venues
+func (s *Server) SetVenues(venues []Venue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.config.Venues = venues
+}
+
+// Reset restores the initial configuration and discards pending failures
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.config = s.initial
+	s.failures = nil
+}
+
+// ServeHTTP implements the http.Handler interface
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	config, failure := s.nextRequest()
+
+	if config.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(config.Latency):
+		}
+	}
+
+	if failure != 0 {
+		writeError(w, failure, "server_error", "fakefoursquare: injected error")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "not_allowed", "only GET requests are supported")
+		return
+	}
+
+	query := r.URL.Query()
+	if config.ClientID != "" && (query.Get("client_id") != config.ClientID || query.Get("client_secret") != config.ClientSecret) {
+		writeError(w, http.StatusBadRequest, "invalid_auth", "Missing access credentials.")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+	switch {
+	case path == "/venues/search":
+		s.search(w, r, config.Venues)
+	case strings.HasPrefix(path, "/venues/"):
+		s.details(w, strings.TrimPrefix(path, "/venues/"), config.Venues)
+	default:
+		writeError(w, http.StatusNotFound, "endpoint_error", "Endpoint not found")
+	}
+}
+
+// nextRequest returns the current config and the
+// status code of an injected failure, if any.
+func (s *Server) nextRequest() (Config, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.failures) > 0 {
+		failure := s.failures[0]
+		s.failures = s.failures[1:]
+		return s.config, failure
+	}
+
+	if s.config.ErrorRate > 0 && s.rand.Float64() < s.config.ErrorRate {
+		return s.config, s.config.ErrorStatus
+	}
+
+	return s.config, 0
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, venues []Venue) {
+	query := r.URL.Query()
+
+	lat, lng, ok := parseLatLng(query.Get("ll"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "param_error", "Must provide parameter ll")
+		return
+	}
+
+	radius := 100000.0
+	if v := query.Get("radius"); v != "" {
+		radius, _ = strconv.ParseFloat(v, 64)
+	}
+
+	limit := 30
+	if v, err := strconv.Atoi(query.Get("limit")); err == nil && v > 0 && v <= 50 {
+		limit = v
+	}
+
+	name := strings.ToLower(query.Get("query"))
+
+	type result struct {
+		venue    Venue
+		distance int
+	}
+	var results []result
+	for _, venue := range venues {
+		if name != "" && !strings.Contains(strings.ToLower(venue.Name), name) {
+			continue
+		}
+
+		distance := geo.Distance(lat, lng, venue.Latitude, venue.Longitude)
+		if distance > radius {
+			continue
+		}
+
+		results = append(results, result{venue: venue, distance: int(math.Round(distance))})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].distance < results[j].distance
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	compactVenues := []interface{}{}
+	for _, r := range results {
+		compactVenues = append(compactVenues, compact(r.venue, r.distance))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"venues": compactVenues,
+	})
+}
+
+func (s *Server) details(w http.ResponseWriter, id string, venues []Venue) {
+	for _, venue := range venues {
+		if venue.ID == id {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"venue": venue.Fields,
+			})
+			return
+		}
+	}
+
+	writeError(w, http.StatusBadRequest, "param_error", fmt.Sprintf("Value %s is invalid for venue id", id))
+}
+
+// compact returns the subset of fields returned by the search endpoint
+func compact(venue Venue, distance int) map[string]interface{} {
+	location := map[string]interface{}{}
+	if l, ok := venue.Fields["location"].(map[string]interface{}); ok {
+		for k, v := range l {
+			location[k] = v
+		}
+	}
+	location["distance"] = distance
+
+	result := map[string]interface{}{
+		"id":       venue.ID,
+		"name":     venue.Name,
+		"location": location,
+	}
+	if categories, ok := venue.Fields["categories"]; ok {
+		result["categories"] = categories
+	}
+	return result
+}
+
+func parseLatLng(ll string) (lat float64, lng float64, ok bool) {
+	rawLat, rawLng, found := strings.Cut(ll, ",")
+	if !found {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(rawLat), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(strings.TrimSpace(rawLng), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"meta": map[string]interface{}{
+			"code":      statusCode,
+			"requestId": newRequestID(),
+		},
+		"response": response,
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorType string, detail string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"meta": map[string]interface{}{
+			"code":        statusCode,
+			"errorType":   errorType,
+			"errorDetail": detail,
+			"requestId":   newRequestID(),
+		},
+		"response": map[string]interface{}{},
+	})
+}
+
+func newRequestID() string {
+	return fmt.Sprintf("%024x", rand.Int63())
+}
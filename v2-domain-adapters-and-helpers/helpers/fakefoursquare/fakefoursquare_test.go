@@ -0,0 +1,117 @@
+package fakefoursquare
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+type searchResponse struct {
+	Meta struct {
+		Code      int    `json:"code"`
+		ErrorType string `json:"errorType"`
+	} `json:"meta"`
+	Response struct {
+		Venues []struct {
+			ID       string `json:"id"`
+			Location struct {
+				Distance int `json:"distance"`
+			} `json:"location"`
+		} `json:"venues"`
+		Venue map[string]interface{} `json:"venue"`
+	} `json:"response"`
+}
+
+func get(t *testing.T, url string) (int, searchResponse) {
+	resp, err := http.Get(url)
+	tt.AssertNoErr(t, err)
+	defer resp.Body.Close()
+
+	var body searchResponse
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	tt.AssertNoErr(t, err)
+	return resp.StatusCode, body
+}
+
+func TestServer(t *testing.T) {
+	fake := New(Config{
+		ClientID:     "fakeID",
+		ClientSecret: "fakeSecret",
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	auth := "client_id=fakeID&client_secret=fakeSecret&v=20210514"
+
+	t.Run("should return the closest venues sorted by distance", func(t *testing.T) {
+		fake.Reset()
+
+		status, body := get(t, server.URL+"/v2/venues/search?ll=40.7484,-73.9857&radius=1000&limit=3&"+auth)
+		tt.AssertEqual(t, status, 200)
+		tt.AssertEqual(t, len(body.Response.Venues), 3)
+		tt.AssertEqual(t, body.Response.Venues[0].ID, "43695300f964a5208c291fe3")
+		tt.AssertEqual(t, body.Response.Venues[0].Location.Distance, 6)
+		tt.AssertEqual(t, body.Response.Venues[1].ID, "4b7efa2ef964a520580130e3")
+	})
+
+	t.Run("should filter venues by name", func(t *testing.T) {
+		fake.Reset()
+
+		status, body := get(t, server.URL+"/venues/search?ll=40.7484,-73.9857&query=park&radius=1000&"+auth)
+		tt.AssertEqual(t, status, 200)
+		tt.AssertEqual(t, len(body.Response.Venues), 2)
+	})
+
+	t.Run("should return the details of a venue", func(t *testing.T) {
+		fake.Reset()
+
+		status, body := get(t, server.URL+"/venues/43695300f964a5208c291fe3?"+auth)
+		tt.AssertEqual(t, status, 200)
+		tt.AssertEqual(t, body.Response.Venue["name"], "Empire State Building")
+		tt.AssertEqual(t, body.Response.Venue["rating"], 9.4)
+
+		status, body = get(t, server.URL+"/venues/missingVenueID?"+auth)
+		tt.AssertEqual(t, status, 400)
+		tt.AssertEqual(t, body.Meta.ErrorType, "param_error")
+	})
+
+	t.Run("should reject invalid credentials", func(t *testing.T) {
+		fake.Reset()
+
+		status, body := get(t, server.URL+"/venues/search?ll=40.7484,-73.9857&client_id=fakeID&client_secret=wrongSecret")
+		tt.AssertEqual(t, status, 400)
+		tt.AssertEqual(t, body.Meta.ErrorType, "invalid_auth")
+	})
+
+	t.Run("should inject errors and latency", func(t *testing.T) {
+		fake.Reset()
+		fake.FailNext(2, 503)
+		fake.SetLatency(50 * time.Millisecond)
+
+		startTime := time.Now()
+		for i := 0; i < 2; i++ {
+			status, body := get(t, server.URL+"/venues/search?ll=40.7484,-73.9857&"+auth)
+			tt.AssertEqual(t, status, 503)
+			tt.AssertEqual(t, body.Meta.Code, 503)
+		}
+
+		status, _ := get(t, server.URL+"/venues/search?ll=40.7484,-73.9857&"+auth)
+		tt.AssertEqual(t, status, 200)
+		tt.AssertApproxDuration(t, 100*time.Millisecond, time.Since(startTime), 150*time.Millisecond, "unexpected latency")
+	})
+
+	t.Run("should fail a fraction of the requests", func(t *testing.T) {
+		fake := New(Config{
+			ErrorRate:   1,
+			ErrorStatus: 429,
+		})
+
+		w := httptest.NewRecorder()
+		fake.ServeHTTP(w, httptest.NewRequest("GET", "/venues/search?ll=1,1", nil))
+		tt.AssertEqual(t, w.Code, 429)
+	})
+}
@@ -0,0 +1,77 @@
+package geo
+
+import "math"
+
+// Box is the area between two parallels and two meridians,
+// boxes crossing the antimeridian are not supported.
+type Box struct {
+	MinLat, MinLng float64
+	MaxLat, MaxLng float64
+}
+
+// Center returns the center point of the box
+func (b Box) Center() (lat, lng float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+// Radius returns the distance in meters from the center of
+// the box to its farthest corner, so a circle with this radius
+// around the center covers the whole box.
+func (b Box) Radius() float64 {
+	lat, lng := b.Center()
+
+	// The corners closer to the equator are the farthest from the center:
+	cornerLat := b.MinLat
+	if math.Abs(b.MaxLat) < math.Abs(b.MinLat) {
+		cornerLat = b.MaxLat
+	}
+	return Distance(lat, lng, cornerLat, b.MaxLng)
+}
+
+// Width returns the width in meters of the widest edge of the box
+func (b Box) Width() float64 {
+	lat := b.widestLat()
+	return Distance(lat, b.MinLng, lat, b.MaxLng)
+}
+
+// Height returns the height in meters of the box
+func (b Box) Height() float64 {
+	return Distance(b.MinLat, b.MinLng, b.MaxLat, b.MinLng)
+}
+
+// Contains reports whether the point is inside the box or on its edges
+func (b Box) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// Split divides the box into a grid with the input number of rows and columns,
+// the boxes are returned row by row starting from the south west corner.
+func (b Box) Split(rows int, cols int) []Box {
+	latStep := (b.MaxLat - b.MinLat) / float64(rows)
+	lngStep := (b.MaxLng - b.MinLng) / float64(cols)
+
+	boxes := make([]Box, 0, rows*cols)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			boxes = append(boxes, Box{
+				MinLat: b.MinLat + float64(row)*latStep,
+				MinLng: b.MinLng + float64(col)*lngStep,
+				MaxLat: b.MinLat + float64(row+1)*latStep,
+				MaxLng: b.MinLng + float64(col+1)*lngStep,
+			})
+		}
+	}
+	return boxes
+}
+
+// widestLat returns the latitude closest to the equator inside
+// the box, where it is the widest since the meridians converge at the poles.
+func (b Box) widestLat() float64 {
+	if b.MinLat <= 0 && b.MaxLat >= 0 {
+		return 0
+	}
+	if math.Abs(b.MaxLat) < math.Abs(b.MinLat) {
+		return b.MaxLat
+	}
+	return b.MinLat
+}
@@ -0,0 +1,38 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestBox(t *testing.T) {
+	// About 2.2km x 1.7km around the Empire State Building:
+	midtown := Box{MinLat: 40.74, MinLng: -73.995, MaxLat: 40.76, MaxLng: -73.975}
+
+	t.Run("should measure the box in meters", func(t *testing.T) {
+		tt.AssertTrue(t, math.Abs(midtown.Height()-2224) < 10)
+		tt.AssertTrue(t, math.Abs(midtown.Width()-1686) < 10)
+		tt.AssertTrue(t, math.Abs(midtown.Radius()-1395) < 10)
+	})
+
+	t.Run("should use the widest edge for the width", func(t *testing.T) {
+		aroundEquator := Box{MinLat: -10, MinLng: 0, MaxLat: 60, MaxLng: 1}
+		tt.AssertTrue(t, math.Abs(aroundEquator.Width()-Distance(0, 0, 0, 1)) < 1)
+	})
+
+	t.Run("should check if the points are inside the box", func(t *testing.T) {
+		tt.AssertTrue(t, midtown.Contains(40.7484, -73.9857))
+		tt.AssertTrue(t, midtown.Contains(40.74, -73.995))
+		tt.AssertFalse(t, midtown.Contains(40.7827, -73.965355))
+	})
+
+	t.Run("should split the box into a grid", func(t *testing.T) {
+		boxes := Box{MinLat: 0, MinLng: 0, MaxLat: 2, MaxLng: 3}.Split(2, 3)
+		tt.AssertEqual(t, len(boxes), 6)
+		tt.AssertEqual(t, boxes[0], Box{MinLat: 0, MinLng: 0, MaxLat: 1, MaxLng: 1})
+		tt.AssertEqual(t, boxes[2], Box{MinLat: 0, MinLng: 2, MaxLat: 1, MaxLng: 3})
+		tt.AssertEqual(t, boxes[5], Box{MinLat: 1, MinLng: 2, MaxLat: 2, MaxLng: 3})
+	})
+}
@@ -0,0 +1,46 @@
+package geo
+
+// This package is a helper package:
+// (1) it is very simple
+// (2) it only depends on stdlib
+//
+// It's so simple in fact that I don't care about decoupling from it,
+// so I wont write any interfaces here.
+
+import "math"
+
+// EarthRadius is the mean radius of the Earth in meters
+const EarthRadius = 6371000.0
+
+// Distance returns the great-circle distance in meters
+// between two points using the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Bearing returns the initial bearing in degrees for going from
+// the first point to the second one, from 0 (north) to 360 clockwise,
+// e.g. 90 means the second point is to the east.
+func Bearing(lat1, lng1, lat2, lng2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dLng := toRadians(lng2 - lng1)
+
+	y := math.Sin(dLng) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLng)
+
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
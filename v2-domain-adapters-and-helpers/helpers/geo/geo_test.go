@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		desc             string
+		lat1, lng1       float64
+		lat2, lng2       float64
+		expectedDistance float64
+		tolerance        float64
+	}{
+		{
+			desc:             "should return zero for the same point",
+			lat1:             40.7484,
+			lng1:             -73.9857,
+			lat2:             40.7484,
+			lng2:             -73.9857,
+			expectedDistance: 0,
+			tolerance:        0.001,
+		},
+		{
+			desc:             "should compute short distances",
+			lat1:             40.7484,
+			lng1:             -73.9857,
+			lat2:             40.750824,
+			lng2:             -73.988756,
+			expectedDistance: 370,
+			tolerance:        5,
+		},
+		{
+			desc:             "should compute long distances",
+			lat1:             40.7128, // New York
+			lng1:             -74.0060,
+			lat2:             51.5074, // London
+			lng2:             -0.1278,
+			expectedDistance: 5570000,
+			tolerance:        10000,
+		},
+		{
+			desc:             "should handle antipodal points",
+			lat1:             0,
+			lng1:             0,
+			lat2:             0,
+			lng2:             180,
+			expectedDistance: math.Pi * EarthRadius,
+			tolerance:        1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			distance := Distance(test.lat1, test.lng1, test.lat2, test.lng2)
+			tt.AssertTrue(t, math.Abs(distance-test.expectedDistance) <= test.tolerance)
+		})
+	}
+}
+
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		desc            string
+		lat2, lng2      float64
+		expectedBearing float64
+	}{
+		{desc: "should return 0 for north", lat2: 1, lng2: 0, expectedBearing: 0},
+		{desc: "should return 90 for east", lat2: 0, lng2: 1, expectedBearing: 90},
+		{desc: "should return 180 for south", lat2: -1, lng2: 0, expectedBearing: 180},
+		{desc: "should return 270 for west", lat2: 0, lng2: -1, expectedBearing: 270},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			bearing := Bearing(0, 0, test.lat2, test.lng2)
+			tt.AssertTrue(t, math.Abs(bearing-test.expectedBearing) < 0.0001)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"fmt"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes a point as a geohash with the input number of characters,
+// e.g. Geohash(40.7484, -73.9857, 6) == "dr5ru6".
+//
+// Each extra character makes the cell 4 to 8 times smaller,
+// with 6 characters the cells are about 1.2km x 0.6km.
+func Geohash(lat, lng float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+
+	var hash strings.Builder
+	var bits, char int
+	evenBit := true
+	for hash.Len() < precision {
+		// Even bits split the longitude and odd bits split the latitude:
+		if evenBit {
+			mid := (minLng + maxLng) / 2
+			if lng >= mid {
+				char = char<<1 | 1
+				minLng = mid
+			} else {
+				char = char << 1
+				maxLng = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				char = char<<1 | 1
+				minLat = mid
+			} else {
+				char = char << 1
+				maxLat = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bits++
+		if bits == 5 {
+			hash.WriteByte(geohashAlphabet[char])
+			bits, char = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// DecodeGeohash returns the cell covered by the input geohash
+func DecodeGeohash(hash string) (Box, error) {
+	cell := Box{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}
+	evenBit := true
+	for _, r := range hash {
+		char := strings.IndexRune(geohashAlphabet, r)
+		if char == -1 {
+			return Box{}, fmt.Errorf("invalid character '%c' on geohash '%s'", r, hash)
+		}
+
+		for bit := 4; bit >= 0; bit-- {
+			isSet := char>>bit&1 == 1
+			if evenBit {
+				mid := (cell.MinLng + cell.MaxLng) / 2
+				if isSet {
+					cell.MinLng = mid
+				} else {
+					cell.MaxLng = mid
+				}
+			} else {
+				mid := (cell.MinLat + cell.MaxLat) / 2
+				if isSet {
+					cell.MinLat = mid
+				} else {
+					cell.MaxLat = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+
+	return cell, nil
+}
@@ -0,0 +1,49 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		desc         string
+		lat, lng     float64
+		precision    int
+		expectedHash string
+	}{
+		{desc: "should encode with low precision", lat: 40.7484, lng: -73.9857, precision: 1, expectedHash: "d"},
+		{desc: "should encode with high precision", lat: 40.7484, lng: -73.9857, precision: 9, expectedHash: "dr5ru6j28"},
+		{desc: "should encode the southern and eastern hemispheres", lat: -33.8688, lng: 151.2093, precision: 6, expectedHash: "r3gx2f"},
+		{desc: "should encode the origin", lat: 0, lng: 0, precision: 5, expectedHash: "s0000"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			tt.AssertEqual(t, Geohash(test.lat, test.lng, test.precision), test.expectedHash)
+		})
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	t.Run("should return a cell containing the encoded point", func(t *testing.T) {
+		cell, err := DecodeGeohash(Geohash(40.7484, -73.9857, 6))
+		tt.AssertNoErr(t, err)
+
+		tt.AssertTrue(t, cell.MinLat <= 40.7484 && 40.7484 <= cell.MaxLat)
+		tt.AssertTrue(t, cell.MinLng <= -73.9857 && -73.9857 <= cell.MaxLng)
+
+		// Cells with 6 characters are about 1.2km x 0.6km:
+		lat, lng := cell.Center()
+		tt.AssertTrue(t, math.Abs(Distance(lat, cell.MinLng, lat, cell.MaxLng)-920) < 10)
+		tt.AssertTrue(t, math.Abs(Distance(cell.MinLat, lng, cell.MaxLat, lng)-610) < 10)
+		tt.AssertTrue(t, math.Abs(cell.Radius()-550) < 10)
+	})
+
+	t.Run("should reject invalid characters", func(t *testing.T) {
+		_, err := DecodeGeohash("dr5ra")
+		tt.AssertErrContains(t, err, "invalid character 'a'")
+	})
+}
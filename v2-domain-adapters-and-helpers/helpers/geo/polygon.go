@@ -0,0 +1,39 @@
+package geo
+
+// Point is a position in decimal degrees
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// InPolygon reports whether the point is inside the polygon described by the
+// input rings, as in GeoJSON the first ring is the boundary and the others are holes.
+//
+// The edges are treated as straight lines on the latitude/longitude plane,
+// which is precise enough for the areas shown on a map.
+func InPolygon(lat, lng float64, rings [][]Point) bool {
+	if len(rings) == 0 || !inRing(lat, lng, rings[0]) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if inRing(lat, lng, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRing uses the ray casting algorithm: a ray going east from the
+// point crosses the edges of the ring an odd number of times if the
+// point is inside it. The ring can be either closed or not.
+func inRing(lat, lng float64, ring []Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > lat) != (b.Lat > lat) &&
+			lng < (b.Lng-a.Lng)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
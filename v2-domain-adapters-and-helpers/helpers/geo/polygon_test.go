@@ -0,0 +1,34 @@
+package geo
+
+import (
+	"testing"
+
+	tt "github.com/vingarcia/ddd-go-template/v2-domain-adapters-and-helpers/helpers/testtools"
+)
+
+func TestInPolygon(t *testing.T) {
+	square := []Point{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}
+	hole := []Point{{4, 4}, {4, 6}, {6, 6}, {6, 4}, {4, 4}}
+	triangle := []Point{{0, 0}, {0, 10}, {10, 0}}
+
+	tests := []struct {
+		desc     string
+		lat, lng float64
+		rings    [][]Point
+		expected bool
+	}{
+		{desc: "should find points inside the polygon", lat: 2, lng: 3, rings: [][]Point{square}, expected: true},
+		{desc: "should not find points outside the polygon", lat: 2, lng: 11, rings: [][]Point{square}, expected: false},
+		{desc: "should not find points inside the holes", lat: 5, lng: 5, rings: [][]Point{square, hole}, expected: false},
+		{desc: "should find points between the boundary and the holes", lat: 8, lng: 8, rings: [][]Point{square, hole}, expected: true},
+		{desc: "should not find points outside rings that are not closed", lat: 8, lng: 8, rings: [][]Point{triangle}, expected: false},
+		{desc: "should find points inside rings that are not closed", lat: 2, lng: 2, rings: [][]Point{triangle}, expected: true},
+		{desc: "should not find points on empty polygons", lat: 0, lng: 0, rings: nil, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			tt.AssertEqual(t, InPolygon(test.lat, test.lng, test.rings), test.expected)
+		})
+	}
+}